@@ -0,0 +1,107 @@
+// Package archive provides tar and zip archive entries as flu.Inputs
+// and writers for streaming flu.Inputs into archives.
+package archive
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/jfk9w-go/flu"
+)
+
+// Entry is a named archive entry.
+// It is a flu.Input providing the entry content.
+type Entry struct {
+	// Name is the entry path inside the archive.
+	Name string
+	// Size is the content size.
+	// When writing, zero value means the size is unknown.
+	Size int64
+	// Mode is the entry file mode.
+	// When writing, zero value means 0644.
+	Mode os.FileMode
+	// ModTime is the entry modification time.
+	// When writing, zero value means the current time.
+	ModTime time.Time
+	// Input is the entry content.
+	// When reading, it is valid only during the Walk callback.
+	flu.Input
+}
+
+// Encoded creates an Entry with the content encoded from EncoderTo.
+func Encoded(name string, encoder flu.EncoderTo) Entry {
+	return Entry{Name: name, Input: flu.PipeInput(encoder)}
+}
+
+// Writer describes an archive writer.
+type Writer interface {
+	// Write writes the Entry to the archive.
+	Write(entry Entry) error
+	// Close completes the archive and closes the underlying Output.
+	Close() error
+}
+
+// Add writes the Input to the archive as the named entry.
+func Add(w Writer, name string, in flu.Input) error {
+	return w.Write(Entry{Name: name, Input: in})
+}
+
+// Encode writes the encoded value to the archive as the named entry.
+func Encode(w Writer, name string, encoder flu.EncoderTo) error {
+	return w.Write(Encoded(name, encoder))
+}
+
+var errStop = errors.New("stop")
+
+func notFound(name string) error {
+	return &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+}
+
+func (e Entry) mode() os.FileMode {
+	if e.Mode == 0 {
+		return 0644
+	}
+
+	return e.Mode
+}
+
+func (e Entry) modTime() time.Time {
+	if e.ModTime.IsZero() {
+		return time.Now()
+	}
+
+	return e.ModTime
+}
+
+// entryReader is an entry content reader which also closes the archive.
+type entryReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r entryReader) Close() error {
+	var errs flu.MultiError
+	for _, closer := range r.closers {
+		errs.Collect(closer.Close())
+	}
+
+	return errs.Reduce()
+}
+
+func writeAll(w Writer, entries []Entry) error {
+	for _, entry := range entries {
+		if err := w.Write(entry); err != nil {
+			_ = w.Close()
+			return err
+		}
+	}
+
+	return w.Close()
+}
+
+// writerOnly hides all methods of io.Writer except Write (including Close).
+type writerOnly struct {
+	io.Writer
+}
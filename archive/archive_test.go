@@ -0,0 +1,125 @@
+package archive_test
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/jfk9w-go/flu"
+	"github.com/jfk9w-go/flu/archive"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTar(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flu")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	source := flu.FilePath(dir, "source.txt")
+	err = flu.EncodeTo(&flu.PlainText{Value: "source"}, source)
+	assert.Nil(t, err)
+
+	file := flu.FilePath(dir, "bundle.tar.gz")
+	w, err := archive.NewTarWriter(file, true)
+	assert.Nil(t, err)
+	assert.Nil(t, archive.Add(w, "source.txt", source))
+	assert.Nil(t, archive.Add(w, "bytes.txt", flu.Bytes("bytes")))
+	assert.Nil(t, archive.Encode(w, "config.json", flu.JSON{Value: map[string]int{"a": 1}}))
+	assert.Nil(t, w.Close())
+
+	entries, err := archive.Tar{In: file}.List()
+	assert.Nil(t, err)
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name
+	}
+
+	assert.Equal(t, []string{"source.txt", "bytes.txt", "config.json"}, names)
+	assert.Equal(t, int64(6), entries[0].Size)
+
+	config := make(map[string]int)
+	err = flu.DecodeFrom(archive.Tar{In: file}.Entry("config.json"), flu.JSON{Value: &config})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"a": 1}, config)
+
+	err = flu.DecodeFrom(archive.Tar{In: file}.Entry("missing"), new(flu.PlainText))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestZip(t *testing.T) {
+	buf := new(flu.ByteBuffer)
+	err := flu.EncodeTo(archive.Zip{Entries: []archive.Entry{
+		{Name: "a.txt", Input: flu.Bytes("a")},
+		archive.Encoded("b.txt", &flu.PlainText{Value: "b"}),
+	}}, buf)
+	assert.Nil(t, err)
+
+	contents := make(map[string]string)
+	err = archive.Zip{In: buf.Bytes()}.Walk(func(entry archive.Entry) error {
+		text := new(flu.PlainText)
+		if err := flu.DecodeFrom(entry, text); err != nil {
+			return err
+		}
+
+		contents[entry.Name] = text.Value
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"a.txt": "a", "b.txt": "b"}, contents)
+
+	text := new(flu.PlainText)
+	err = flu.DecodeFrom(archive.Zip{In: buf.Bytes()}.Entry("b.txt"), text)
+	assert.Nil(t, err)
+	assert.Equal(t, "b", text.Value)
+}
+
+func TestZip_LargeInputIsSpilled(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flu")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+	data := make([]byte, 2*flu.DefaultReplayMemoryLimit.Bytes)
+	_, _ = rand.Read(data)
+	buf := new(flu.ByteBuffer)
+	err = flu.EncodeTo(archive.Zip{Entries: []archive.Entry{
+		{Name: "random.bin", Input: flu.Bytes(data)},
+	}}, buf)
+	assert.Nil(t, err)
+
+	t.Setenv("TMPDIR", dir)
+	content := new(flu.ByteBuffer)
+	_, err = flu.Copy(archive.Zip{In: buf.Bytes()}.Entry("random.bin"), content)
+	assert.Nil(t, err)
+	assert.Equal(t, data, []byte(content.Bytes()))
+
+	files, err := ioutil.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Empty(t, files)
+}
+
+func TestTar_ZlibLikeEntryName(t *testing.T) {
+	// "XG" is a valid zlib header
+	buf := new(flu.ByteBuffer)
+	err := flu.EncodeTo(archive.Tar{Entries: []archive.Entry{
+		{Name: "XGBoost/model.txt", Input: flu.Bytes("model")},
+	}}, buf)
+	assert.Nil(t, err)
+
+	entries, err := archive.Tar{In: buf.Bytes()}.List()
+	assert.Nil(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "XGBoost/model.txt", entries[0].Name)
+	}
+
+	text := new(flu.PlainText)
+	err = flu.DecodeFrom(archive.Tar{In: buf.Bytes()}.Entry("XGBoost/model.txt"), text)
+	assert.Nil(t, err)
+	assert.Equal(t, "model", text.Value)
+}
@@ -0,0 +1,249 @@
+package archive
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+
+	"github.com/jfk9w-go/flu"
+)
+
+// Tar is a tar archive.
+// When reading, gzip compression is detected automatically.
+type Tar struct {
+	// In is the archive Input (used for reading).
+	In flu.Input
+	// Entries are the entries to be encoded (used for writing).
+	Entries []Entry
+	// Gzip enables gzip compression when writing.
+	Gzip bool
+}
+
+// Walk iterates over the archive entries.
+// Entry Input is valid only during the callback.
+func (t Tar) Walk(fun func(entry Entry) error) error {
+	r, err := t.reader()
+	if err != nil {
+		return err
+	}
+
+	defer flu.Close(r)
+	return walkTar(r, fun)
+}
+
+// reader opens the archive detecting gzip compression.
+// Only the gzip magic is checked: other formats detected by flu.Compressed
+// (like zlib) have headers which may match plain tar entry names.
+func (t Tar) reader() (io.Reader, error) {
+	r, err := t.In.Reader()
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err != nil && err != io.EOF {
+		_ = flu.Close(r)
+		return nil, err
+	}
+
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			_ = flu.Close(r)
+			return nil, err
+		}
+
+		return entryReader{gr, []io.Closer{gr, flu.AnyCloser{V: r}}}, nil
+	}
+
+	return entryReader{br, []io.Closer{flu.AnyCloser{V: r}}}, nil
+}
+
+func walkTar(r io.Reader, fun func(entry Entry) error) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		entry := Entry{
+			Name:    header.Name,
+			Size:    header.Size,
+			Mode:    header.FileInfo().Mode(),
+			ModTime: header.ModTime,
+			Input:   flu.IO{R: tr},
+		}
+
+		if err := fun(entry); err != nil {
+			return err
+		}
+	}
+}
+
+// List returns the archive entries without content.
+func (t Tar) List() ([]Entry, error) {
+	var entries []Entry
+	return entries, t.Walk(func(entry Entry) error {
+		entry.Input = nil
+		entries = append(entries, entry)
+		return nil
+	})
+}
+
+// Entry returns the named entry content as flu.Input.
+// The archive is scanned on every Reader call.
+func (t Tar) Entry(name string) flu.Input {
+	return tarEntry{t, name}
+}
+
+type tarEntry struct {
+	tar  Tar
+	name string
+}
+
+func (e tarEntry) Reader() (io.Reader, error) {
+	r, err := e.tar.reader()
+	if err != nil {
+		return nil, err
+	}
+
+	var content io.Reader
+	if err := walkTar(r, func(entry Entry) error {
+		if entry.Name == e.name && !entry.Mode.IsDir() {
+			content, _ = entry.Reader()
+			return errStop
+		}
+
+		return nil
+	}); err != nil && err != errStop {
+		_ = flu.Close(r)
+		return nil, err
+	}
+
+	if content == nil {
+		_ = flu.Close(r)
+		return nil, notFound(e.name)
+	}
+
+	return entryReader{content, []io.Closer{flu.AnyCloser{V: r}}}, nil
+}
+
+func (t Tar) EncodeTo(w io.Writer) error {
+	return writeAll(newTarWriter(w, nil, t.Gzip), t.Entries)
+}
+
+func (t Tar) ContentType() string {
+	if t.Gzip {
+		return "application/gzip"
+	}
+
+	return "application/x-tar"
+}
+
+// TarWriter streams entries into a tar archive.
+type TarWriter struct {
+	tw *tar.Writer
+	gw *gzip.Writer
+	w  io.Writer
+}
+
+// NewTarWriter opens the Output for writing a tar archive.
+func NewTarWriter(out flu.Output, gzip bool) (*TarWriter, error) {
+	w, err := out.Writer()
+	if err != nil {
+		return nil, err
+	}
+
+	return newTarWriter(w, w, gzip), nil
+}
+
+func newTarWriter(w io.Writer, closer io.Writer, compress bool) *TarWriter {
+	tw := &TarWriter{w: closer}
+	if compress {
+		tw.gw = gzip.NewWriter(w)
+		w = tw.gw
+	}
+
+	tw.tw = tar.NewWriter(w)
+	return tw
+}
+
+// Write writes the Entry to the archive.
+// Tar headers require the content size, so if it is unknown,
+// the content is cached first (see flu.Replayable).
+func (w *TarWriter) Write(entry Entry) error {
+	in := entry.Input
+	if file, ok := in.(flu.File); ok && entry.Size <= 0 {
+		info, err := os.Stat(file.Path())
+		if err != nil {
+			return err
+		}
+
+		entry.Size = info.Size()
+	}
+
+	if entry.Size <= 0 {
+		replayable := flu.Replayable(in, flu.DefaultReplayMemoryLimit)
+		defer replayable.Close()
+		r, err := replayable.Reader()
+		if err != nil {
+			return err
+		}
+
+		size, err := readerSize(r)
+		_ = flu.Close(r)
+		if err != nil {
+			return err
+		}
+
+		entry.Size, in = size, replayable
+	}
+
+	if err := w.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     entry.Name,
+		Size:     entry.Size,
+		Mode:     int64(entry.mode().Perm()),
+		ModTime:  entry.modTime(),
+	}); err != nil {
+		return err
+	}
+
+	_, err := flu.Copy(in, flu.IO{W: writerOnly{w.tw}})
+	return err
+}
+
+func readerSize(r io.Reader) (int64, error) {
+	switch r := r.(type) {
+	case *bytes.Reader:
+		return r.Size(), nil
+	case *os.File:
+		info, err := r.Stat()
+		if err != nil {
+			return 0, err
+		}
+
+		return info.Size(), nil
+	default:
+		return io.Copy(io.Discard, r)
+	}
+}
+
+// Close completes the archive and closes the underlying Output.
+func (w *TarWriter) Close() error {
+	var errs flu.MultiError
+	errs.Collect(w.tw.Close())
+	if w.gw != nil {
+		errs.Collect(w.gw.Close())
+	}
+
+	errs.Collect(flu.CloseWithError(w.w, errs.Reduce()))
+	return errs.Reduce()
+}
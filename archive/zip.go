@@ -0,0 +1,192 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/jfk9w-go/flu"
+	"github.com/jfk9w-go/flu/serde"
+)
+
+// Zip is a zip archive.
+// Reading requires random access, so Inputs other than flu.File
+// are cached first (in memory up to flu.DefaultReplayMemoryLimit,
+// in a temporary file otherwise).
+type Zip struct {
+	// In is the archive Input (used for reading).
+	In flu.Input
+	// Entries are the entries to be encoded (used for writing).
+	Entries []Entry
+}
+
+func (z Zip) open() (*zip.Reader, io.Closer, error) {
+	if file, ok := z.In.(flu.File); ok {
+		rc, err := zip.OpenReader(file.Path())
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return &rc.Reader, rc, nil
+	}
+
+	// small archives are kept in memory, larger ones are spilled to a temporary file
+	replay := flu.Replayable(z.In, serde.Size{})
+	r, err := replay.Reader()
+	if err != nil {
+		_ = replay.Close()
+		return nil, nil, err
+	}
+
+	closer := entryReader{r, []io.Closer{flu.AnyCloser{V: r}, replay}}
+	var size int64
+	switch r := r.(type) {
+	case *bytes.Reader:
+		size = r.Size()
+	case *os.File:
+		stat, err := r.Stat()
+		if err != nil {
+			_ = closer.Close()
+			return nil, nil, err
+		}
+
+		size = stat.Size()
+	}
+
+	zr, err := zip.NewReader(r.(io.ReaderAt), size)
+	if err != nil {
+		_ = closer.Close()
+		return nil, nil, err
+	}
+
+	return zr, closer, nil
+}
+
+// Walk iterates over the archive entries.
+// Entry Input is valid only during the callback.
+func (z Zip) Walk(fun func(entry Entry) error) error {
+	zr, closer, err := z.open()
+	if err != nil {
+		return err
+	}
+
+	defer closer.Close()
+	for _, file := range zr.File {
+		if err := fun(zipEntry(file)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func zipEntry(file *zip.File) Entry {
+	return Entry{
+		Name:    file.Name,
+		Size:    int64(file.UncompressedSize64),
+		Mode:    file.Mode(),
+		ModTime: file.Modified,
+		Input:   zipFileInput{file},
+	}
+}
+
+type zipFileInput struct {
+	file *zip.File
+}
+
+func (in zipFileInput) Reader() (io.Reader, error) {
+	return in.file.Open()
+}
+
+// List returns the archive entries without content.
+func (z Zip) List() ([]Entry, error) {
+	var entries []Entry
+	return entries, z.Walk(func(entry Entry) error {
+		entry.Input = nil
+		entries = append(entries, entry)
+		return nil
+	})
+}
+
+// Entry returns the named entry content as flu.Input.
+func (z Zip) Entry(name string) flu.Input {
+	return zipEntryInput{z, name}
+}
+
+type zipEntryInput struct {
+	zip  Zip
+	name string
+}
+
+func (e zipEntryInput) Reader() (io.Reader, error) {
+	zr, closer, err := e.zip.open()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range zr.File {
+		if file.Name == e.name && !file.Mode().IsDir() {
+			r, err := file.Open()
+			if err != nil {
+				_ = closer.Close()
+				return nil, err
+			}
+
+			return entryReader{r, []io.Closer{r, closer}}, nil
+		}
+	}
+
+	_ = closer.Close()
+	return nil, notFound(e.name)
+}
+
+func (z Zip) EncodeTo(w io.Writer) error {
+	return writeAll(&ZipWriter{zw: zip.NewWriter(w)}, z.Entries)
+}
+
+func (z Zip) ContentType() string {
+	return "application/zip"
+}
+
+// ZipWriter streams entries into a zip archive.
+type ZipWriter struct {
+	zw *zip.Writer
+	w  io.Writer
+}
+
+// NewZipWriter opens the Output for writing a zip archive.
+func NewZipWriter(out flu.Output) (*ZipWriter, error) {
+	w, err := out.Writer()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ZipWriter{zw: zip.NewWriter(w), w: w}, nil
+}
+
+// Write writes the Entry to the archive.
+func (w *ZipWriter) Write(entry Entry) error {
+	header := &zip.FileHeader{
+		Name:     entry.Name,
+		Method:   zip.Deflate,
+		Modified: entry.modTime(),
+	}
+
+	header.SetMode(entry.mode())
+	ew, err := w.zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = flu.Copy(entry.Input, flu.IO{W: writerOnly{ew}})
+	return err
+}
+
+// Close completes the archive and closes the underlying Output.
+func (w *ZipWriter) Close() error {
+	var errs flu.MultiError
+	errs.Collect(w.zw.Close())
+	errs.Collect(flu.CloseWithError(w.w, errs.Reduce()))
+	return errs.Reduce()
+}
@@ -0,0 +1,148 @@
+package flu
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// AtomicFile is a File which is replaced atomically on write.
+// The data is written to a temporary file in the same directory
+// which is synced and renamed to the target path on Close.
+// If any write fails or the writer is closed with an error
+// (see CloseWithError), the temporary file is removed
+// and the target stays untouched.
+type AtomicFile File
+
+// Atomic returns an AtomicFile view of this File.
+func (f File) Atomic() AtomicFile {
+	return AtomicFile(f)
+}
+
+// File returns the underlying File.
+func (f AtomicFile) File() File {
+	return File(f)
+}
+
+func (f AtomicFile) Reader() (io.Reader, error) {
+	return f.File().Open()
+}
+
+func (f AtomicFile) Writer() (io.Writer, error) {
+	return f.Create()
+}
+
+// Create opens a temporary file for writing.
+// It creates all intermediate directories if necessary.
+func (f AtomicFile) Create() (*AtomicFileWriter, error) {
+	path := f.File().Path()
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	file, err := os.CreateTemp(dir, "."+base+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+
+	w := &AtomicFileWriter{file: file, path: path}
+	if err := file.Chmod(mode); err != nil {
+		_ = w.abort()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// AtomicFileWriter writes to a temporary file
+// which replaces the target file on Close.
+type AtomicFileWriter struct {
+	file   *os.File
+	path   string
+	err    error
+	closed bool
+}
+
+func (w *AtomicFileWriter) Write(data []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	n, err := w.file.Write(data)
+	if err != nil {
+		w.err = err
+	}
+
+	return n, err
+}
+
+// Close commits the written data to the target file
+// unless any of the writes failed.
+func (w *AtomicFileWriter) Close() error {
+	return w.CloseWithError(nil)
+}
+
+// CloseWithError discards the written data if err is not nil.
+// Otherwise, it is equivalent to Close.
+// The write error (if any) or err is returned when the data is discarded.
+func (w *AtomicFileWriter) CloseWithError(err error) error {
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+	if w.err != nil {
+		err = w.err
+	}
+
+	if err != nil {
+		_ = w.abort()
+		return err
+	}
+
+	if err := w.file.Sync(); err != nil {
+		_ = w.abort()
+		return err
+	}
+
+	if err := w.file.Close(); err != nil {
+		_ = os.Remove(w.file.Name())
+		return err
+	}
+
+	if err := os.Rename(w.file.Name(), w.path); err != nil {
+		_ = os.Remove(w.file.Name())
+		return err
+	}
+
+	return syncDir(filepath.Dir(w.path))
+}
+
+func (w *AtomicFileWriter) abort() error {
+	_ = w.file.Close()
+	return os.Remove(w.file.Name())
+}
+
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	if err := dir.Sync(); err != nil {
+		_ = dir.Close()
+		return err
+	}
+
+	return dir.Close()
+}
@@ -0,0 +1,52 @@
+package flu_test
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/jfk9w-go/flu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtomicFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flu")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	file := flu.FilePath(dir, "state", "snapshot.txt")
+	err = flu.EncodeTo(&flu.PlainText{Value: "first"}, file.Atomic())
+	assert.Nil(t, err)
+
+	encodeErr := errors.New("encode failed")
+	err = flu.EncodeTo(encoderFunc(func(w io.Writer) error {
+		_, _ = io.WriteString(w, "partial")
+		return encodeErr
+	}), file.Atomic())
+	assert.Equal(t, encodeErr, err)
+
+	text := new(flu.PlainText)
+	err = flu.DecodeFrom(file, text)
+	assert.Nil(t, err)
+	assert.Equal(t, "first", text.Value)
+
+	entries, err := ioutil.ReadDir(file.Join("..").Path())
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+
+	writer, err := file.Atomic().Create()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	_, err = io.WriteString(writer, "aborted")
+	assert.Nil(t, err)
+	assert.Equal(t, encodeErr, writer.CloseWithError(encodeErr))
+	assert.Nil(t, flu.DecodeFrom(file, text))
+	assert.Equal(t, "first", text.Value)
+}
@@ -0,0 +1,95 @@
+package flu
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"regexp"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Charset returns the encoding.Encoding by its name or label
+// (like "windows-1251" or "utf-16le").
+func Charset(name string) (encoding.Encoding, error) {
+	return htmlindex.Get(name)
+}
+
+// AutoChars is the text character Input wrapper which detects the encoding.
+// UTF-8 and UTF-16 byte order marks are detected and stripped.
+// Without a byte order mark the encoding is resolved from Charset,
+// then from the XML prolog (<?xml version="1.0" encoding="..."?>),
+// and falls back to Default.
+type AutoChars struct {
+	// In is the underlying Input.
+	In Input
+	// Charset is the declared charset name (for example, from Content-Type).
+	// May be empty.
+	Charset string
+	// Default is the fallback encoding.
+	// The data is passed as is (as UTF-8) if empty.
+	Default encoding.Encoding
+}
+
+var xmlEncoding = regexp.MustCompile(`^\s*<\?xml[^>]*?\sencoding\s*=\s*["']([A-Za-z0-9._:-]+)["']`)
+
+// charsetPeekSize is the number of bytes examined for byte order marks and XML prolog.
+const charsetPeekSize = 1024
+
+func (ac AutoChars) Reader() (io.Reader, error) {
+	return ac.ReaderContext(context.Background())
+}
+
+func (ac AutoChars) ReaderContext(ctx context.Context) (io.Reader, error) {
+	r, err := ContextReader(ctx, ac.In)
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReaderSize(r, charsetPeekSize)
+	enc, err := ac.detect(br)
+	if err != nil {
+		_ = CloseWithError(r, err)
+		return nil, err
+	}
+
+	if enc == nil {
+		return chainReader{br, []interface{}{r}}, nil
+	}
+
+	return chainReader{enc.NewDecoder().Reader(br), []interface{}{r}}, nil
+}
+
+func (ac AutoChars) detect(br *bufio.Reader) (encoding.Encoding, error) {
+	prefix, err := br.Peek(charsetPeekSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+
+	for _, bom := range []struct {
+		mark []byte
+		enc  encoding.Encoding
+	}{
+		{[]byte{0xEF, 0xBB, 0xBF}, nil},
+		{[]byte{0xFE, 0xFF}, unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)},
+		{[]byte{0xFF, 0xFE}, unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)},
+	} {
+		if bytes.HasPrefix(prefix, bom.mark) {
+			_, err := br.Discard(len(bom.mark))
+			return bom.enc, err
+		}
+	}
+
+	if ac.Charset != "" {
+		return Charset(ac.Charset)
+	}
+
+	if match := xmlEncoding.FindSubmatch(prefix); match != nil {
+		return Charset(string(match[1]))
+	}
+
+	return ac.Default, nil
+}
@@ -0,0 +1,87 @@
+package flu_test
+
+import (
+	"testing"
+
+	"github.com/jfk9w-go/flu"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestAutoChars(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		data     []byte
+		charset  string
+		expected string
+	}{
+		{
+			name:     "utf-8 bom",
+			data:     []byte("\xef\xbb\xbfhello"),
+			charset:  "windows-1251",
+			expected: "hello",
+		},
+		{
+			name:     "utf-16le bom",
+			data:     []byte{0xff, 0xfe, 'h', 0, 'i', 0, 0x1f, 0x04},
+			expected: "hiП",
+		},
+		{
+			name:     "utf-16be bom",
+			data:     []byte{0xfe, 0xff, 0, 'h', 0, 'i'},
+			expected: "hi",
+		},
+		{
+			name:     "charset",
+			data:     []byte{0xcf, 0xf0, 0xe8, 0xe2, 0xe5, 0xf2},
+			charset:  "windows-1251",
+			expected: "Привет",
+		},
+		{
+			name:     "xml prolog",
+			data:     append([]byte(`<?xml version="1.0" encoding="windows-1251"?><a>`), 0xcf, '<', '/', 'a', '>'),
+			expected: `<?xml version="1.0" encoding="windows-1251"?><a>П</a>`,
+		},
+		{
+			name:     "no declaration",
+			data:     []byte("plain"),
+			expected: "plain",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			text := new(flu.PlainText)
+			err := flu.DecodeFrom(flu.AutoChars{In: flu.Bytes(tc.data), Charset: tc.charset}, text)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, text.Value)
+		})
+	}
+}
+
+func TestAutoChars_Default(t *testing.T) {
+	text := new(flu.PlainText)
+	in := flu.AutoChars{In: flu.Bytes{0xcf}, Default: charmap.Windows1251}
+	assert.Nil(t, flu.DecodeFrom(in, text))
+	assert.Equal(t, "П", text.Value)
+
+	in = flu.AutoChars{In: flu.Bytes("x"), Charset: "unknown-charset"}
+	assert.NotNil(t, flu.DecodeFrom(in, text))
+}
+
+func TestAutoChars_XML(t *testing.T) {
+	type Value struct {
+		Name string `xml:"name"`
+	}
+
+	data := append([]byte(`<?xml version="1.0" encoding="windows-1251"?><value><name>`),
+		0xcf, 0xf0, 0xe8, 0xe2, 0xe5, 0xf2)
+	data = append(data, []byte(`</name></value>`)...)
+
+	var value Value
+	err := flu.DecodeFrom(flu.AutoChars{In: flu.Bytes(data)}, flu.XML{Value: &value})
+	assert.Nil(t, err)
+	assert.Equal(t, "Привет", value.Name)
+
+	// not transcoded
+	err = flu.DecodeFrom(flu.Bytes(data), flu.XML{Value: &value})
+	assert.NotNil(t, err)
+}
@@ -1,47 +1,157 @@
 package flu
 
 import (
+	"encoding/gob"
 	"encoding/json"
 	"encoding/xml"
 	"io"
+	"reflect"
 
 	yaml "gopkg.in/yaml.v3"
 )
 
 // JSON encodes/decodes the provided value using JSON format.
+// See JSONWith for encoding and decoding options.
 type JSON struct {
 	Value interface{}
 }
 
 func (j JSON) EncodeTo(w io.Writer) error {
-	return json.NewEncoder(w).Encode(j.Value)
+	return JSONWith{Value: j.Value}.EncodeTo(w)
 }
 
 func (j JSON) DecodeFrom(r io.Reader) error {
-	return json.NewDecoder(r).Decode(j.Value)
+	return JSONWith{Value: j.Value}.DecodeFrom(r)
 }
 
 func (j JSON) ContentType() string {
 	return "application/json"
 }
 
+// JSONOptions configure JSON encoding and decoding.
+type JSONOptions struct {
+	// DisallowUnknownFields makes decoding fail on object keys
+	// which do not match any struct field.
+	DisallowUnknownFields bool
+	// UseNumber makes the decoder unmarshal numbers into interface{}
+	// as json.Number instead of float64.
+	UseNumber bool
+	// Indent enables pretty printing with the provided indent (like "  ").
+	Indent string
+	// DisableHTMLEscape disables escaping of <, > and & in strings.
+	DisableHTMLEscape bool
+	// Multi enables streaming of multiple documents.
+	// For encoding, Value may be a slice, an array or a channel.
+	// For decoding, Value may be a pointer to slice, a channel
+	// or a func(T) error callback (see JSONLines).
+	Multi bool
+}
+
+// JSONWith encodes/decodes the provided value using JSON format
+// with the Options.
+type JSONWith struct {
+	Value   interface{}
+	Options JSONOptions
+}
+
+func (j JSONWith) EncodeTo(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", j.Options.Indent)
+	encoder.SetEscapeHTML(!j.Options.DisableHTMLEscape)
+	if j.Options.Multi {
+		return forEachValue(j.Value, func(value reflect.Value) error {
+			return encoder.Encode(value.Interface())
+		})
+	}
+
+	return encoder.Encode(j.Value)
+}
+
+func (j JSONWith) DecodeFrom(r io.Reader) error {
+	decoder := json.NewDecoder(r)
+	if j.Options.DisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+
+	if j.Options.UseNumber {
+		decoder.UseNumber()
+	}
+
+	if j.Options.Multi {
+		return decodeAll(j.Value, decoder.Decode)
+	}
+
+	return decoder.Decode(j.Value)
+}
+
+func (j JSONWith) ContentType() string {
+	return "application/json"
+}
+
 // XML encodes/decodes the provided value using XML format.
+// See XMLWith for encoding options.
 type XML struct {
 	Value interface{}
 }
 
 func (x XML) EncodeTo(w io.Writer) error {
-	return xml.NewEncoder(w).Encode(x.Value)
+	return XMLWith{Value: x.Value}.EncodeTo(w)
 }
 
+// DecodeFrom decodes the value from UTF-8 input regardless of the encoding
+// declared in the XML prolog. Use AutoChars to transcode other charsets
+// (it detects the encoding from the prolog).
 func (x XML) DecodeFrom(r io.Reader) error {
-	return xml.NewDecoder(r).Decode(x.Value)
+	return XMLWith{Value: x.Value}.DecodeFrom(r)
 }
 
 func (x XML) ContentType() string {
 	return "application/xml"
 }
 
+// XMLOptions configure XML encoding.
+type XMLOptions struct {
+	// Indent enables pretty printing with the provided indent (like "  ").
+	Indent string
+	// Prolog enables writing the standard XML header (xml.Header)
+	// before the value.
+	Prolog bool
+}
+
+// XMLWith encodes/decodes the provided value using XML format
+// with the Options.
+type XMLWith struct {
+	Value   interface{}
+	Options XMLOptions
+}
+
+func (x XMLWith) EncodeTo(w io.Writer) error {
+	if x.Options.Prolog {
+		if _, err := io.WriteString(w, xml.Header); err != nil {
+			return err
+		}
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", x.Options.Indent)
+	return encoder.Encode(x.Value)
+}
+
+// DecodeFrom decodes the value from UTF-8 input (see XML.DecodeFrom).
+func (x XMLWith) DecodeFrom(r io.Reader) error {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		// invalid UTF-8 will be reported by the decoder
+		return input, nil
+	}
+
+	return decoder.Decode(x.Value)
+}
+
+func (x XMLWith) ContentType() string {
+	return "application/xml"
+}
+
 // PlainText encodes/decodes the provided value as plain text.
 type PlainText struct {
 	Value string
@@ -66,19 +176,116 @@ func (t *PlainText) ContentType() string {
 }
 
 // YAML encodes/decodes the provided value using YAML format.
+// See YAMLWith for encoding and decoding options.
 type YAML struct {
 	Value interface{}
 }
 
 func (y YAML) EncodeTo(w io.Writer) error {
-	enc := yaml.NewEncoder(w)
-	if err := enc.Encode(y.Value); err != nil {
+	return YAMLWith{Value: y.Value}.EncodeTo(w)
+}
+
+func (y YAML) DecodeFrom(r io.Reader) error {
+	return YAMLWith{Value: y.Value}.DecodeFrom(r)
+}
+
+func (y YAML) ContentType() string {
+	return "application/yaml"
+}
+
+// YAMLOptions configure YAML encoding and decoding.
+type YAMLOptions struct {
+	// KnownFields makes decoding fail on mapping keys
+	// which do not match any struct field.
+	KnownFields bool
+	// Indent is the indentation width used for encoding.
+	// Defaults to 4.
+	Indent int
+	// Multi enables streaming of multiple documents separated with "---".
+	// For encoding, Value may be a slice, an array or a channel.
+	// For decoding, Value may be a pointer to slice, a channel
+	// or a func(T) error callback (see JSONLines).
+	Multi bool
+}
+
+// YAMLWith encodes/decodes the provided value using YAML format
+// with the Options.
+type YAMLWith struct {
+	Value   interface{}
+	Options YAMLOptions
+}
+
+func (y YAMLWith) EncodeTo(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	if y.Options.Indent > 0 {
+		encoder.SetIndent(y.Options.Indent)
+	}
+
+	var err error
+	if y.Options.Multi {
+		err = forEachValue(y.Value, func(value reflect.Value) error {
+			return encoder.Encode(value.Interface())
+		})
+	} else {
+		err = encoder.Encode(y.Value)
+	}
+
+	if err != nil {
 		return err
 	}
 
-	return enc.Close()
+	return encoder.Close()
 }
 
-func (y YAML) DecodeFrom(r io.Reader) error {
-	return yaml.NewDecoder(r).Decode(y.Value)
+func (y YAMLWith) DecodeFrom(r io.Reader) error {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(y.Options.KnownFields)
+	if y.Options.Multi {
+		return decodeAll(y.Value, decoder.Decode)
+	}
+
+	return decoder.Decode(y.Value)
+}
+
+func (y YAMLWith) ContentType() string {
+	return "application/yaml"
+}
+
+// Gob encodes/decodes the provided value using encoding/gob format.
+type Gob struct {
+	Value interface{}
+}
+
+func (g Gob) EncodeTo(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(g.Value)
+}
+
+func (g Gob) DecodeFrom(r io.Reader) error {
+	return gob.NewDecoder(r).Decode(g.Value)
+}
+
+func (g Gob) ContentType() string {
+	return "application/x-gob"
+}
+
+// decodeAll decodes documents into the values sink until io.EOF.
+func decodeAll(values interface{}, decode func(value interface{}) error) error {
+	sink, err := newValueSink(values)
+	if err != nil {
+		return err
+	}
+
+	defer sink.Close()
+	for {
+		ptr := sink.New()
+		if err := decode(ptr.Interface()); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if err := sink.Put(ptr); err != nil {
+			return err
+		}
+	}
 }
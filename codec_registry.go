@@ -0,0 +1,163 @@
+package flu
+
+import (
+	"fmt"
+	"mime"
+	"path/filepath"
+	"strings"
+)
+
+// Codec interface describes a value which can be both encoded and decoded.
+type Codec interface {
+	EncoderTo
+	DecoderFrom
+}
+
+// CodecFunc wraps the value with a Codec.
+type CodecFunc func(value interface{}) Codec
+
+// UnknownCodecError is returned when no codec is registered
+// for the content type or file extension.
+type UnknownCodecError string
+
+func (e UnknownCodecError) Error() string {
+	return fmt.Sprintf("no codec registered for %s", string(e))
+}
+
+// CodecRegistry maps MIME types and file extensions to codecs.
+// It is safe for concurrent use.
+type CodecRegistry struct {
+	contentTypes map[string]CodecFunc
+	extensions   map[string]CodecFunc
+	mu           RWMutex
+}
+
+// NewCodecRegistry creates an empty CodecRegistry.
+func NewCodecRegistry() *CodecRegistry {
+	return &CodecRegistry{
+		contentTypes: make(map[string]CodecFunc),
+		extensions:   make(map[string]CodecFunc),
+	}
+}
+
+// Register registers the codec for the MIME type and file extensions.
+// Content type parameters (like charset) are ignored.
+// Extensions are matched case-insensitively and may omit the leading dot.
+func (r *CodecRegistry) Register(codec CodecFunc, contentType string, extensions ...string) *CodecRegistry {
+	defer r.mu.Lock().Unlock()
+	if contentType != "" {
+		r.contentTypes[mediaType(contentType)] = codec
+	}
+
+	for _, ext := range extensions {
+		r.extensions[normalizeExtension(ext)] = codec
+	}
+
+	return r
+}
+
+// ByContentType looks up the codec by the MIME type.
+func (r *CodecRegistry) ByContentType(contentType string) (CodecFunc, error) {
+	defer r.mu.RLock().Unlock()
+	if codec, ok := r.contentTypes[mediaType(contentType)]; ok {
+		return codec, nil
+	}
+
+	return nil, UnknownCodecError(contentType)
+}
+
+// ByExtension looks up the codec by the file extension.
+func (r *CodecRegistry) ByExtension(ext string) (CodecFunc, error) {
+	defer r.mu.RLock().Unlock()
+	if codec, ok := r.extensions[normalizeExtension(ext)]; ok {
+		return codec, nil
+	}
+
+	return nil, UnknownCodecError(ext)
+}
+
+// ByPath looks up the codec by the file path extension.
+func (r *CodecRegistry) ByPath(path string) (CodecFunc, error) {
+	return r.ByExtension(filepath.Ext(path))
+}
+
+func mediaType(contentType string) string {
+	if value, _, err := mime.ParseMediaType(contentType); err == nil {
+		return value
+	}
+
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+func normalizeExtension(ext string) string {
+	ext = strings.ToLower(ext)
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+
+	return ext
+}
+
+// DefaultCodecs is the default CodecRegistry containing built-in codecs.
+var DefaultCodecs = NewCodecRegistry().
+	Register(func(value interface{}) Codec { return JSON{Value: value} }, "application/json", ".json").
+	Register(func(value interface{}) Codec { return XML{Value: value} }, "application/xml", ".xml").
+	Register(func(value interface{}) Codec { return XML{Value: value} }, "text/xml").
+	Register(func(value interface{}) Codec { return YAML{Value: value} }, "application/yaml", ".yaml", ".yml").
+	Register(func(value interface{}) Codec { return YAML{Value: value} }, "application/x-yaml").
+	Register(func(value interface{}) Codec { return YAML{Value: value} }, "text/yaml").
+	Register(func(value interface{}) Codec { return JSONLines{Value: value} }, "application/x-ndjson", ".jsonl", ".ndjson").
+	Register(func(value interface{}) Codec { return CSV{Value: value} }, "text/csv", ".csv").
+	Register(func(value interface{}) Codec { return Gob{Value: value} }, "application/x-gob", ".gob").
+	Register(protobufCodec, "application/x-protobuf", ".pb").
+	Register(protobufCodec, "application/protobuf")
+
+// DecodeFile decodes the value from the file using the codec
+// chosen by the file extension in DefaultCodecs.
+// Compressed files (like "config.json.gz") are decompressed transparently.
+func DecodeFile(path string, value interface{}) error {
+	codec, err := CodecFor(path)
+	if err != nil {
+		return err
+	}
+
+	return DecodeFrom(Compressed{In: File(path)}, codec(value))
+}
+
+// EncodeFile encodes the value to the file using the codec
+// chosen by the file extension in DefaultCodecs.
+// Files with ".gz" and ".z" extensions are compressed with gzip and zlib respectively.
+// Writing bzip2 (".bz2") is not supported.
+func EncodeFile(path string, value interface{}) error {
+	codec, err := CodecFor(path)
+	if err != nil {
+		return err
+	}
+
+	var out Output = File(path)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".gz":
+		out = Gzip{Out: out}
+	case ".z":
+		out = Zlib{Out: out}
+	case ".bz2":
+		return fmt.Errorf("writing %s files is not supported", ext)
+	}
+
+	return EncodeTo(codec(value), out)
+}
+
+// CodecFor looks up the codec in DefaultCodecs by the file extension
+// skipping the compression extension (".gz", ".z" or ".bz2").
+func CodecFor(path string) (CodecFunc, error) {
+	return DefaultCodecs.ByPath(uncompressedPath(path))
+}
+
+func uncompressedPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gz", ".z", ".bz2":
+		return strings.TrimSuffix(path, filepath.Ext(path))
+	default:
+		return path
+	}
+}
@@ -0,0 +1,65 @@
+package flu_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfk9w-go/flu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodecRegistry_ByContentType(t *testing.T) {
+	codec, err := flu.DefaultCodecs.ByContentType("application/json; charset=utf-8")
+	assert.Nil(t, err)
+	assert.IsType(t, flu.JSON{}, codec(nil))
+
+	_, err = flu.DefaultCodecs.ByContentType("image/png")
+	assert.Equal(t, flu.UnknownCodecError("image/png"), err)
+}
+
+func TestCodecFor(t *testing.T) {
+	codec, err := flu.CodecFor("config.YAML.gz")
+	assert.Nil(t, err)
+	assert.Equal(t, flu.YAML{Value: 1}, codec(1))
+
+	_, err = flu.CodecFor("config.gz")
+	assert.Equal(t, flu.UnknownCodecError(""), err)
+}
+
+func TestEncodeFile_DecodeFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flu")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	type Config struct {
+		Name  string `json:"name" yaml:"name"`
+		Count int    `json:"count" yaml:"count"`
+	}
+
+	value := Config{Name: "test", Count: 3}
+	for _, name := range []string{"config.yml", "config.json", "config.json.gz", "config.yaml.z"} {
+		path := filepath.Join(dir, name)
+		assert.Nil(t, flu.EncodeFile(path, value), name)
+
+		decoded := new(Config)
+		assert.Nil(t, flu.DecodeFile(path, decoded), name)
+		assert.Equal(t, value, *decoded, name)
+	}
+
+	compressed, err := ioutil.ReadFile(filepath.Join(dir, "config.yaml.z"))
+	assert.Nil(t, err)
+	assert.Equal(t, byte(0x78), compressed[0], "zlib header")
+
+	path := filepath.Join(dir, "config.json.bz2")
+	assert.NotNil(t, flu.EncodeFile(path, value))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	err = flu.DecodeFile(filepath.Join(dir, "config.ini"), new(Config))
+	assert.Equal(t, flu.UnknownCodecError(".ini"), err)
+}
@@ -0,0 +1,83 @@
+package flu_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jfk9w-go/flu"
+	"github.com/stretchr/testify/assert"
+)
+
+type codecTestValue struct {
+	Name  string      `json:"name" yaml:"name" xml:"name"`
+	Extra interface{} `json:"extra,omitempty" yaml:"extra,omitempty" xml:"-"`
+}
+
+func TestJSON_Options(t *testing.T) {
+	value := new(codecTestValue)
+	err := flu.DecodeFrom(flu.Bytes(`{"name": "test", "nmae": "typo"}`), flu.JSONWith{Value: value, Options: flu.JSONOptions{DisallowUnknownFields: true}})
+	assert.NotNil(t, err)
+
+	err = flu.DecodeFrom(flu.Bytes(`{"name": "test", "extra": 12345678901234567890}`), flu.JSONWith{Value: value, Options: flu.JSONOptions{UseNumber: true}})
+	assert.Nil(t, err)
+	assert.Equal(t, json.Number("12345678901234567890"), value.Extra)
+
+	buf := new(flu.ByteBuffer)
+	err = flu.EncodeTo(flu.JSONWith{Value: codecTestValue{Name: "<a>"}, Options: flu.JSONOptions{Indent: "  ", DisableHTMLEscape: true}}, buf)
+	assert.Nil(t, err)
+	assert.Equal(t, "{\n  \"name\": \"<a>\"\n}\n", buf.Unmask().String())
+}
+
+func TestJSON_Multi(t *testing.T) {
+	buf := new(flu.ByteBuffer)
+	values := []codecTestValue{{Name: "a"}, {Name: "b"}}
+	err := flu.EncodeTo(flu.JSONWith{Value: values, Options: flu.JSONOptions{Indent: "  ", Multi: true}}, buf)
+	assert.Nil(t, err)
+
+	var decoded []codecTestValue
+	err = flu.DecodeFrom(buf.Bytes(), flu.JSONWith{Value: &decoded, Options: flu.JSONOptions{Multi: true}})
+	assert.Nil(t, err)
+	assert.Equal(t, values, decoded)
+}
+
+func TestXML_Options(t *testing.T) {
+	type Value struct {
+		codecTestValue
+		XMLName struct{} `xml:"value"`
+	}
+
+	buf := new(flu.ByteBuffer)
+	err := flu.EncodeTo(flu.XMLWith{Value: Value{codecTestValue: codecTestValue{Name: "test"}}, Options: flu.XMLOptions{Indent: "  ", Prolog: true}}, buf)
+	assert.Nil(t, err)
+	assert.Equal(t, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<value>\n  <name>test</name>\n</value>", buf.Unmask().String())
+}
+
+func TestYAML_Options(t *testing.T) {
+	value := new(codecTestValue)
+	err := flu.DecodeFrom(flu.Bytes("name: test\nnmae: typo\n"), flu.YAMLWith{Value: value, Options: flu.YAMLOptions{KnownFields: true}})
+	assert.NotNil(t, err)
+
+	buf := new(flu.ByteBuffer)
+	err = flu.EncodeTo(flu.YAMLWith{Value: map[string]interface{}{"a": map[string]int{"b": 1}}, Options: flu.YAMLOptions{Indent: 2}}, buf)
+	assert.Nil(t, err)
+	assert.Equal(t, "a:\n  b: 1\n", buf.Unmask().String())
+}
+
+func TestYAML_Multi(t *testing.T) {
+	buf := new(flu.ByteBuffer)
+	values := make(chan codecTestValue, 2)
+	values <- codecTestValue{Name: "a"}
+	values <- codecTestValue{Name: "b"}
+	close(values)
+	err := flu.EncodeTo(flu.YAMLWith{Value: values, Options: flu.YAMLOptions{Multi: true}}, buf)
+	assert.Nil(t, err)
+	assert.Equal(t, "name: a\n---\nname: b\n", buf.Unmask().String())
+
+	var names []string
+	err = flu.DecodeFrom(buf.Bytes(), flu.YAMLWith{Value: func(value codecTestValue) error {
+		names = append(names, value.Name)
+		return nil
+	}, Options: flu.YAMLOptions{Multi: true}})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, names)
+}
@@ -0,0 +1,223 @@
+package flu
+
+import (
+	"bufio"
+	"compress/bzip2"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+)
+
+// Gzip is the gzip compression Input / Output wrapper.
+type Gzip struct {
+	// In is the underlying Input.
+	In Input
+	// Out is the underlying Output.
+	Out Output
+	// Level is the compression level used when writing.
+	// Zero value means gzip.DefaultCompression,
+	// use NoCompression to disable compression.
+	Level int
+}
+
+func (g Gzip) Reader() (io.Reader, error) {
+	return g.ReaderContext(context.Background())
+}
+
+func (g Gzip) ReaderContext(ctx context.Context) (io.Reader, error) {
+	r, err := ContextReader(ctx, g.In)
+	if err != nil {
+		return nil, err
+	}
+
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		_ = Close(r)
+		return nil, err
+	}
+
+	return chainReader{gr, []interface{}{gr, r}}, nil
+}
+
+func (g Gzip) Writer() (io.Writer, error) {
+	return g.WriterContext(context.Background())
+}
+
+func (g Gzip) WriterContext(ctx context.Context) (io.Writer, error) {
+	w, err := ContextWriter(ctx, g.Out)
+	if err != nil {
+		return nil, err
+	}
+
+	gw, err := gzip.NewWriterLevel(w, compressionLevel(g.Level))
+	if err != nil {
+		_ = Close(w)
+		return nil, err
+	}
+
+	return chainWriter{gw, []interface{}{gw, w}}, nil
+}
+
+// Zlib is the zlib compression Input / Output wrapper.
+type Zlib struct {
+	// In is the underlying Input.
+	In Input
+	// Out is the underlying Output.
+	Out Output
+	// Level is the compression level used when writing.
+	// Zero value means zlib.DefaultCompression,
+	// use NoCompression to disable compression.
+	Level int
+}
+
+func (z Zlib) Reader() (io.Reader, error) {
+	return z.ReaderContext(context.Background())
+}
+
+func (z Zlib) ReaderContext(ctx context.Context) (io.Reader, error) {
+	r, err := ContextReader(ctx, z.In)
+	if err != nil {
+		return nil, err
+	}
+
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		_ = Close(r)
+		return nil, err
+	}
+
+	return chainReader{zr, []interface{}{zr, r}}, nil
+}
+
+func (z Zlib) Writer() (io.Writer, error) {
+	return z.WriterContext(context.Background())
+}
+
+func (z Zlib) WriterContext(ctx context.Context) (io.Writer, error) {
+	w, err := ContextWriter(ctx, z.Out)
+	if err != nil {
+		return nil, err
+	}
+
+	zw, err := zlib.NewWriterLevel(w, compressionLevel(z.Level))
+	if err != nil {
+		_ = Close(w)
+		return nil, err
+	}
+
+	return chainWriter{zw, []interface{}{zw, w}}, nil
+}
+
+// Deflate is the raw DEFLATE compression Input / Output wrapper.
+type Deflate struct {
+	// In is the underlying Input.
+	In Input
+	// Out is the underlying Output.
+	Out Output
+	// Level is the compression level used when writing.
+	// Zero value means flate.DefaultCompression,
+	// use NoCompression to disable compression.
+	Level int
+}
+
+func (d Deflate) Reader() (io.Reader, error) {
+	return d.ReaderContext(context.Background())
+}
+
+func (d Deflate) ReaderContext(ctx context.Context) (io.Reader, error) {
+	r, err := ContextReader(ctx, d.In)
+	if err != nil {
+		return nil, err
+	}
+
+	fr := flate.NewReader(r)
+	return chainReader{fr, []interface{}{fr, r}}, nil
+}
+
+func (d Deflate) Writer() (io.Writer, error) {
+	return d.WriterContext(context.Background())
+}
+
+func (d Deflate) WriterContext(ctx context.Context) (io.Writer, error) {
+	w, err := ContextWriter(ctx, d.Out)
+	if err != nil {
+		return nil, err
+	}
+
+	fw, err := flate.NewWriter(w, compressionLevel(d.Level))
+	if err != nil {
+		_ = Close(w)
+		return nil, err
+	}
+
+	return chainWriter{fw, []interface{}{fw, w}}, nil
+}
+
+// NoCompression is the compression level which disables compression
+// (since zero Level means the default compression level).
+const NoCompression = flate.HuffmanOnly - 1
+
+func compressionLevel(level int) int {
+	switch level {
+	case 0:
+		return flate.DefaultCompression
+	case NoCompression:
+		return flate.NoCompression
+	default:
+		return level
+	}
+}
+
+// Compressed is the decompressing Input wrapper
+// which detects the compression format by magic bytes.
+// gzip, zlib and bzip2 formats are supported.
+// The data is passed through as is if no known format is detected.
+// Raw DEFLATE streams have no magic bytes, so use Deflate for them.
+type Compressed struct {
+	// In is the underlying Input.
+	In Input
+}
+
+func (c Compressed) Reader() (io.Reader, error) {
+	return c.ReaderContext(context.Background())
+}
+
+func (c Compressed) ReaderContext(ctx context.Context) (io.Reader, error) {
+	r, err := ContextReader(ctx, c.In)
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(3)
+	if err != nil && err != io.EOF {
+		_ = Close(r)
+		return nil, err
+	}
+
+	var dr io.Reader
+	switch {
+	case len(magic) >= 2 && magic[0] == 0x1f && magic[1] == 0x8b:
+		dr, err = gzip.NewReader(br)
+	case len(magic) >= 2 && isZlibHeader(magic[0], magic[1]):
+		dr, err = zlib.NewReader(br)
+	case len(magic) >= 3 && string(magic) == "BZh":
+		dr = bzip2.NewReader(br)
+	default:
+		dr = br
+	}
+
+	if err != nil {
+		_ = Close(r)
+		return nil, err
+	}
+
+	return chainReader{dr, []interface{}{dr, r}}, nil
+}
+
+func isZlibHeader(cmf, flg byte) bool {
+	// deflate method, window size <= 32K, no preset dictionary, valid checksum
+	return cmf&0x0f == 8 && cmf>>4 <= 7 && flg&0x20 == 0 && (uint16(cmf)<<8|uint16(flg))%31 == 0
+}
@@ -0,0 +1,138 @@
+package flu_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jfk9w-go/flu"
+	"github.com/stretchr/testify/assert"
+)
+
+type compressTestValue struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func TestCompressed_Gzip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flu")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	file := flu.FilePath(dir, "value.json.gz")
+	value := compressTestValue{Name: "test", Count: 5}
+	err = flu.EncodeTo(flu.JSON{Value: value}, flu.Gzip{Out: file, Level: gzip.BestCompression})
+	assert.Nil(t, err)
+
+	decoded := new(compressTestValue)
+	err = flu.DecodeFrom(flu.Compressed{In: file}, flu.JSON{Value: decoded})
+	assert.Nil(t, err)
+	assert.Equal(t, value, *decoded)
+}
+
+func TestCompressed_Zlib(t *testing.T) {
+	buf := new(flu.ByteBuffer)
+	err := flu.EncodeTo(&flu.PlainText{Value: "zlib text"}, flu.Zlib{Out: buf})
+	assert.Nil(t, err)
+
+	text := new(flu.PlainText)
+	err = flu.DecodeFrom(flu.Compressed{In: buf.Bytes()}, text)
+	assert.Nil(t, err)
+	assert.Equal(t, "zlib text", text.Value)
+}
+
+func TestCompressed_Plain(t *testing.T) {
+	text := new(flu.PlainText)
+	err := flu.DecodeFrom(flu.Compressed{In: flu.Bytes("plain text")}, text)
+	assert.Nil(t, err)
+	assert.Equal(t, "plain text", text.Value)
+}
+
+func TestDeflate(t *testing.T) {
+	buf := new(flu.ByteBuffer)
+	err := flu.EncodeTo(&flu.PlainText{Value: "deflate text"}, flu.Deflate{Out: buf})
+	assert.Nil(t, err)
+
+	text := new(flu.PlainText)
+	err = flu.DecodeFrom(flu.Deflate{In: buf.Bytes()}, text)
+	assert.Nil(t, err)
+	assert.Equal(t, "deflate text", text.Value)
+}
+
+func TestGzip_NoCompression(t *testing.T) {
+	value := strings.Repeat("stored ", 100)
+	stored, compressed := new(flu.ByteBuffer), new(flu.ByteBuffer)
+	assert.Nil(t, flu.EncodeTo(&flu.PlainText{Value: value}, flu.Gzip{Out: stored, Level: flu.NoCompression}))
+	assert.Nil(t, flu.EncodeTo(&flu.PlainText{Value: value}, flu.Gzip{Out: compressed}))
+	assert.True(t, bytes.Contains(stored.Bytes(), []byte(value)))
+	assert.True(t, len(compressed.Bytes()) < len(value))
+
+	text := new(flu.PlainText)
+	assert.Nil(t, flu.DecodeFrom(flu.Gzip{In: stored.Bytes()}, text))
+	assert.Equal(t, value, text.Value)
+}
+
+// contextIO records the context passed to ReaderContext and WriterContext.
+type contextIO struct {
+	buf *flu.ByteBuffer
+	ctx *context.Context
+}
+
+func (c contextIO) Reader() (io.Reader, error) {
+	return c.ReaderContext(context.Background())
+}
+
+func (c contextIO) ReaderContext(ctx context.Context) (io.Reader, error) {
+	*c.ctx = ctx
+	return c.buf.Reader()
+}
+
+func (c contextIO) Writer() (io.Writer, error) {
+	return c.WriterContext(context.Background())
+}
+
+func (c contextIO) WriterContext(ctx context.Context) (io.Writer, error) {
+	*c.ctx = ctx
+	return c.buf.Writer()
+}
+
+func TestCompression_Context(t *testing.T) {
+	type ctxKey struct{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, true))
+	defer cancel()
+
+	for name, wrap := range map[string]func(contextIO) (flu.Input, flu.Output){
+		"gzip":    func(c contextIO) (flu.Input, flu.Output) { return flu.Gzip{In: c}, flu.Gzip{Out: c} },
+		"zlib":    func(c contextIO) (flu.Input, flu.Output) { return flu.Zlib{In: c}, flu.Zlib{Out: c} },
+		"deflate": func(c contextIO) (flu.Input, flu.Output) { return flu.Deflate{In: c}, flu.Deflate{Out: c} },
+		"compressed": func(c contextIO) (flu.Input, flu.Output) {
+			return flu.Compressed{In: c}, flu.Gzip{Out: c}
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var used context.Context
+			in, out := wrap(contextIO{buf: new(flu.ByteBuffer), ctx: &used})
+			assert.Nil(t, flu.EncodeToContext(ctx, &flu.PlainText{Value: "text"}, out))
+			assert.Equal(t, true, used.Value(ctxKey{}))
+
+			used = nil
+			text := new(flu.PlainText)
+			assert.Nil(t, flu.DecodeFromContext(ctx, in, text))
+			assert.Equal(t, true, used.Value(ctxKey{}))
+			assert.Equal(t, "text", text.Value)
+		})
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := flu.DecodeFromContext(cancelled, flu.Gzip{In: flu.Bytes("")}, new(flu.PlainText))
+	assert.Equal(t, context.Canceled, err)
+}
@@ -0,0 +1,492 @@
+// Package config builds configuration structs from layered sources.
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/jfk9w-go/flu"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// DefaultSource is the source of the values
+// which were present in the target before loading.
+const DefaultSource = "default"
+
+// Sources maps configuration value paths (like "db.host")
+// to the sources they were set from: DefaultSource, "file:<path>",
+// "env:<variable>" or "flag:<name>".
+type Sources map[string]string
+
+// Loader builds a configuration struct from layered sources:
+// the target contents (defaults), then Files, then environment variables,
+// then command-line flags. Later layers override earlier ones field by field.
+//
+// Field names are taken from yaml tags (lowercased field names by default).
+// Nested struct fields are addressed by dot-separated paths (like "db.host").
+// Scalar fields (including serde.Duration, serde.Size, serde.Time and other
+// types implementing yaml.Unmarshaler or encoding.TextUnmarshaler)
+// and slices of scalars (comma-separated) can be set
+// from environment variables and flags.
+type Loader struct {
+	// Files are YAML or JSON files (or any other format registered
+	// in flu.DefaultCodecs which decodes into maps) applied in order.
+	Files []flu.File
+	// IgnoreMissingFiles makes Loader skip Files which do not exist.
+	IgnoreMissingFiles bool
+	// EnvPrefix is the environment variable prefix.
+	// The variable name is the prefix followed by the path in upper case
+	// with dots replaced by underscores (for example, "APP" and "db.host"
+	// make APP_DB_HOST).
+	// Environment variables are not read if empty.
+	EnvPrefix string
+	// Environ is the environment in "key=value" form.
+	// Defaults to os.Environ().
+	Environ []string
+	// Args are the command-line arguments without the program name
+	// (like os.Args[1:]). Flags are named after the paths
+	// (like --db.host=localhost). Parsing stops at the first non-flag argument.
+	// Flags are not parsed if nil.
+	Args []string
+	// Strict makes loading fail on unknown keys in Files.
+	// The error names the file containing the unknown key.
+	Strict bool
+}
+
+// Load fills the target (which must be a pointer to struct)
+// and validates it with flu.Validate.
+// It returns the sources of all set values.
+func (l Loader) Load(target interface{}) (Sources, error) {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return nil, errors.Errorf("target must be a pointer to struct, got %T", target)
+	}
+
+	sources := make(Sources)
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	defaults := new(yaml.Node)
+	if err := defaults.Encode(target); err != nil {
+		return nil, errors.Wrap(err, "encode defaults")
+	}
+
+	defaults = stringifyScalars(defaults, value)
+
+	merge(root, defaults, "", DefaultSource, sources)
+	for _, file := range l.Files {
+		if err := l.loadFile(root, file, value.Elem().Type(), sources); err != nil {
+			return nil, errors.Wrapf(err, "load %s", file.Path())
+		}
+	}
+
+	fields := collectFields(value.Elem().Type(), nil, nil)
+	if l.EnvPrefix != "" {
+		l.loadEnv(root, fields, sources)
+	}
+
+	if l.Args != nil {
+		if err := l.loadFlags(root, fields, sources); err != nil {
+			return nil, errors.Wrap(err, "parse flags")
+		}
+	}
+
+	data, err := yaml.Marshal(root)
+	if err != nil {
+		return nil, errors.Wrap(err, "encode config")
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(l.Strict)
+	if err := decoder.Decode(target); err != nil {
+		return nil, errors.Wrap(err, "decode config")
+	}
+
+	if err := flu.Validate(target); err != nil {
+		return nil, err
+	}
+
+	return sources, nil
+}
+
+func (l Loader) loadFile(root *yaml.Node, file flu.File, typ reflect.Type, sources Sources) error {
+	codec, err := flu.CodecFor(file.Path())
+	if err != nil {
+		return err
+	}
+
+	var data interface{}
+	decoder := codec(&data)
+	if _, ok := decoder.(flu.JSON); ok {
+		// keep integers above 2^53 exact
+		decoder = flu.JSONWith{Value: &data, Options: flu.JSONOptions{UseNumber: true}}
+	}
+
+	if err := flu.DecodeFrom(flu.Compressed{In: file}, decoder); err != nil {
+		if l.IgnoreMissingFiles && os.IsNotExist(errors.Cause(err)) {
+			return nil
+		}
+
+		return err
+	}
+
+	if data == nil {
+		return nil
+	}
+
+	node := new(yaml.Node)
+	if err := node.Encode(convertNumbers(data)); err != nil {
+		return err
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return errors.New("expected a mapping at the top level")
+	}
+
+	if l.Strict {
+		if err := checkKnownFields(node, typ); err != nil {
+			return err
+		}
+	}
+
+	merge(root, node, "", "file:"+file.Path(), sources)
+	return nil
+}
+
+// convertNumbers replaces json.Number values with int64, uint64 or float64.
+func convertNumbers(value interface{}) interface{} {
+	switch value := value.(type) {
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i
+		}
+
+		if u, err := strconv.ParseUint(value.String(), 10, 64); err == nil {
+			return u
+		}
+
+		f, _ := value.Float64()
+		return f
+	case map[string]interface{}:
+		for key, elem := range value {
+			value[key] = convertNumbers(elem)
+		}
+	case []interface{}:
+		for i, elem := range value {
+			value[i] = convertNumbers(elem)
+		}
+	}
+
+	return value
+}
+
+// checkKnownFields checks if all mapping keys match the type fields.
+func checkKnownFields(node *yaml.Node, typ reflect.Type) error {
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	return decoder.Decode(reflect.New(typ).Interface())
+}
+
+func (l Loader) loadEnv(root *yaml.Node, fields []field, sources Sources) {
+	environ := l.Environ
+	if environ == nil {
+		environ = os.Environ()
+	}
+
+	env := make(map[string]string, len(environ))
+	for _, entry := range environ {
+		if i := strings.Index(entry, "="); i > 0 {
+			env[entry[:i]] = entry[i+1:]
+		}
+	}
+
+	prefix := strings.TrimSuffix(l.EnvPrefix, "_")
+	for _, field := range fields {
+		name := strings.ToUpper(prefix + "_" + strings.Join(field.path, "_"))
+		name = strings.Map(func(r rune) rune {
+			if r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+				return r
+			}
+
+			return '_'
+		}, name)
+
+		if value, ok := env[name]; ok {
+			field.set(root, value, "env:"+name, sources)
+		}
+	}
+}
+
+func (l Loader) loadFlags(root *yaml.Node, fields []field, sources Sources) error {
+	flags := flag.NewFlagSet("config", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	for _, field := range fields {
+		name := strings.Join(field.path, ".")
+		flags.Var(&flagValue{field: field, root: root, sources: sources}, name, "")
+	}
+
+	return flags.Parse(l.Args)
+}
+
+type flagValue struct {
+	field   field
+	root    *yaml.Node
+	sources Sources
+}
+
+func (v *flagValue) String() string {
+	return ""
+}
+
+func (v *flagValue) Set(value string) error {
+	v.field.set(v.root, value, "flag:"+strings.Join(v.field.path, "."), v.sources)
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.field.typ.Kind() == reflect.Bool
+}
+
+// field is a scalar (or a slice of scalars) configuration field.
+type field struct {
+	path []string
+	typ  reflect.Type
+}
+
+var (
+	yamlUnmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func isScalar(typ reflect.Type) bool {
+	if reflect.PtrTo(typ).Implements(yamlUnmarshalerType) || reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		return true
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+func collectFields(typ reflect.Type, path []string, fields []field) []field {
+	for i := 0; i < typ.NumField(); i++ {
+		structField := typ.Field(i)
+		if structField.PkgPath != "" {
+			continue
+		}
+
+		name, inline := fieldName(structField)
+		if name == "-" {
+			continue
+		}
+
+		fieldPath := append(append([]string{}, path...), name)
+		if inline {
+			fieldPath = path
+		}
+
+		fieldType := structField.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		switch {
+		case isScalar(fieldType):
+		case fieldType.Kind() == reflect.Slice && isScalar(fieldType.Elem()):
+		case fieldType.Kind() == reflect.Struct:
+			fields = collectFields(fieldType, fieldPath, fields)
+			continue
+		default:
+			continue
+		}
+
+		fields = append(fields, field{path: fieldPath, typ: fieldType})
+	}
+
+	return fields
+}
+
+// fieldName returns the yaml name of the struct field
+// and whether the field is inlined.
+func fieldName(structField reflect.StructField) (string, bool) {
+	tag := strings.Split(structField.Tag.Get("yaml"), ",")
+	name := tag[0]
+	inline := false
+	for _, option := range tag[1:] {
+		inline = inline || option == "inline"
+	}
+
+	if name == "" {
+		name = strings.ToLower(structField.Name)
+	}
+
+	return name, inline
+}
+
+// stringifyScalars replaces the nodes of scalar structs (like serde.Duration)
+// which yaml encodes as mappings with their String() values.
+func stringifyScalars(node *yaml.Node, value reflect.Value) *yaml.Node {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return node
+		}
+
+		value = value.Elem()
+	}
+
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) > 0 {
+			node.Content[0] = stringifyScalars(node.Content[0], value)
+		}
+
+		return node
+	}
+
+	switch {
+	case value.Kind() == reflect.Struct && isScalar(value.Type()):
+		ptr := reflect.New(value.Type())
+		ptr.Elem().Set(value)
+		if stringer, ok := ptr.Interface().(fmt.Stringer); ok {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: stringer.String()}
+		}
+	case value.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		typ := value.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			structField := typ.Field(i)
+			if structField.PkgPath != "" {
+				continue
+			}
+
+			name, inline := fieldName(structField)
+			switch {
+			case name == "-":
+			case inline:
+				stringifyScalars(node, value.Field(i))
+			default:
+				if child := lookup(node, name); child != nil {
+					put(node, name, stringifyScalars(child, value.Field(i)))
+				}
+			}
+		}
+	case value.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode && value.Len() == len(node.Content):
+		for i := range node.Content {
+			node.Content[i] = stringifyScalars(node.Content[i], value.Index(i))
+		}
+	}
+
+	return node
+}
+
+func (f field) set(root *yaml.Node, value string, source string, sources Sources) {
+	var node *yaml.Node
+	if f.typ.Kind() == reflect.Slice && !isScalar(f.typ) {
+		node = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, elem := range strings.Split(value, ",") {
+			node.Content = append(node.Content, scalarNode(f.typ.Elem(), strings.TrimSpace(elem)))
+		}
+	} else {
+		node = scalarNode(f.typ, value)
+	}
+
+	parent := root
+	for _, key := range f.path[:len(f.path)-1] {
+		child := lookup(parent, key)
+		if child == nil || child.Kind != yaml.MappingNode {
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			put(parent, key, child)
+		}
+
+		parent = child
+	}
+
+	path := strings.Join(f.path, ".")
+	put(parent, f.path[len(f.path)-1], node)
+	clearSources(sources, path)
+	sources[path] = source
+}
+
+func scalarNode(typ reflect.Type, value string) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+	if typ.Kind() == reflect.String {
+		// prevent resolving strings like "123" or "true" to other types
+		node.Tag = "!!str"
+	}
+
+	return node
+}
+
+// merge applies src mapping node to dst mapping node recording the sources
+// of the overridden values.
+func merge(dst, src *yaml.Node, path string, source string, sources Sources) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i].Value, src.Content[i+1]
+		keyPath := key
+		if path != "" {
+			keyPath = path + "." + key
+		}
+
+		if value.Kind == yaml.MappingNode {
+			existing := lookup(dst, key)
+			if existing == nil || existing.Kind != yaml.MappingNode {
+				existing = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+				put(dst, key, existing)
+				clearSources(sources, keyPath)
+			}
+
+			merge(existing, value, keyPath, source, sources)
+			continue
+		}
+
+		put(dst, key, value)
+		clearSources(sources, keyPath)
+		sources[keyPath] = source
+	}
+}
+
+func lookup(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+func put(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+
+	mapping.Content = append(mapping.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// clearSources removes the sources of the path and all nested paths.
+func clearSources(sources Sources, path string) {
+	for key := range sources {
+		if key == path || strings.HasPrefix(key, path+".") {
+			delete(sources, key)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package config_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jfk9w-go/flu"
+	"github.com/jfk9w-go/flu/config"
+	"github.com/jfk9w-go/flu/serde"
+	"github.com/stretchr/testify/assert"
+)
+
+type testConfig struct {
+	Name    string         `yaml:"name" validate:"required"`
+	Debug   bool           `yaml:"debug"`
+	Timeout serde.Duration `yaml:"timeout"`
+	Limit   serde.Size     `yaml:"limit"`
+	Tags    []string       `yaml:"tags"`
+	DB      struct {
+		Host string `yaml:"host"`
+		Port int    `yaml:"port"`
+	} `yaml:"db"`
+}
+
+func writeFile(t *testing.T, file flu.File, data string) {
+	if err := flu.EncodeTo(&flu.PlainText{Value: data}, file); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoader_Load(t *testing.T) {
+	err := flu.WithTempDir("flu-config-*", func(dir flu.File) error {
+		base, local := dir.Join("base.yaml"), dir.Join("local.json")
+		writeFile(t, base, "name: service\ntimeout: 10s\ndb:\n  host: db.local\n  port: 5432\n")
+		writeFile(t, local, `{"limit": "10Mb", "db": {"port": 6432}}`)
+
+		cfg := new(testConfig)
+		cfg.Name = "default"
+		cfg.Timeout = serde.Duration{Duration: time.Second}
+		cfg.DB.Host = "localhost"
+
+		sources, err := config.Loader{
+			Files:     []flu.File{base, local, dir.Join("missing.yaml")},
+			EnvPrefix: "APP",
+			Environ:   []string{"APP_DB_HOST=db.remote", "APP_TAGS=a, b", "OTHER_NAME=ignored"},
+			Args:      []string{"--debug", "-name", "123"},
+
+			IgnoreMissingFiles: true,
+		}.Load(cfg)
+		if !assert.Nil(t, err) {
+			return nil
+		}
+
+		assert.Equal(t, "123", cfg.Name)
+		assert.True(t, cfg.Debug)
+		assert.Equal(t, 10*time.Second, cfg.Timeout.Duration)
+		assert.Equal(t, int64(10<<20), cfg.Limit.Bytes)
+		assert.Equal(t, []string{"a", "b"}, cfg.Tags)
+		assert.Equal(t, "db.remote", cfg.DB.Host)
+		assert.Equal(t, 6432, cfg.DB.Port)
+
+		assert.Equal(t, config.Sources{
+			"name":    "flag:name",
+			"debug":   "flag:debug",
+			"timeout": "file:" + base.Path(),
+			"limit":   "file:" + local.Path(),
+			"tags":    "env:APP_TAGS",
+			"db.host": "env:APP_DB_HOST",
+			"db.port": "file:" + local.Path(),
+		}, sources)
+		return nil
+	})
+
+	assert.Nil(t, err)
+}
+
+func TestLoader_Load_Errors(t *testing.T) {
+	err := flu.WithTempDir("flu-config-*", func(dir flu.File) error {
+		file := dir.Join("config.yaml")
+		writeFile(t, file, "name: service\nnmae: typo\n")
+
+		valid := dir.Join("valid.json")
+		writeFile(t, valid, `{"name": "service"}`)
+		_, err := config.Loader{Files: []flu.File{valid, file}, Strict: true}.Load(new(testConfig))
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), file.Path())
+			assert.Contains(t, err.Error(), "nmae")
+		}
+
+		_, err = config.Loader{Files: []flu.File{dir.Join("missing.yaml")}}.Load(new(testConfig))
+		assert.NotNil(t, err)
+
+		_, err = config.Loader{Args: []string{"--unknown"}}.Load(new(testConfig))
+		assert.NotNil(t, err)
+
+		_, err = config.Loader{}.Load(new(testConfig))
+		assert.Equal(t, flu.ValidationError{{Path: "name", Rule: "required"}}, err)
+
+		sources, err := config.Loader{}.Load(&testConfig{Name: "default"})
+		assert.Nil(t, err)
+		assert.Equal(t, config.DefaultSource, sources["name"])
+		assert.Equal(t, config.DefaultSource, sources["db.port"])
+		return nil
+	})
+
+	assert.Nil(t, err)
+}
+
+func TestLoader_Load_JSONNumbers(t *testing.T) {
+	err := flu.WithTempDir("flu-config-*", func(dir flu.File) error {
+		file := dir.Join("config.json.gz")
+		if err := flu.EncodeFile(file.Path(), map[string]interface{}{
+			"id":    json.Number("9007199254740993"),
+			"max":   json.Number("18446744073709551615"),
+			"ratio": 0.1,
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		var cfg struct {
+			ID    int64   `yaml:"id"`
+			Max   uint64  `yaml:"max"`
+			Ratio float64 `yaml:"ratio"`
+		}
+
+		_, err := config.Loader{Files: []flu.File{file}, Strict: true}.Load(&cfg)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(9007199254740993), cfg.ID)
+		assert.Equal(t, uint64(18446744073709551615), cfg.Max)
+		assert.Equal(t, 0.1, cfg.Ratio)
+		return nil
+	})
+
+	assert.Nil(t, err)
+}
+
+func TestLoader_Load_ScalarDefaults(t *testing.T) {
+	cfg := new(struct {
+		Limit     serde.Size       `yaml:"limit"`
+		Since     serde.Time       `yaml:"since"`
+		Intervals []serde.Duration `yaml:"intervals"`
+		Timeout   *serde.Duration  `yaml:"timeout"`
+	})
+
+	cfg.Limit = serde.Size{Bytes: 1536}
+	cfg.Since = serde.Time{Time: time.Date(2020, 5, 1, 12, 30, 0, 0, time.UTC)}
+	cfg.Intervals = []serde.Duration{{Duration: time.Second}, {Duration: time.Minute}}
+	cfg.Timeout = &serde.Duration{Duration: time.Millisecond}
+
+	sources, err := config.Loader{}.Load(cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1536), cfg.Limit.Bytes)
+	assert.Equal(t, time.Date(2020, 5, 1, 12, 30, 0, 0, time.UTC), cfg.Since.Time)
+	assert.Equal(t, []serde.Duration{{Duration: time.Second}, {Duration: time.Minute}}, cfg.Intervals)
+	assert.Equal(t, time.Millisecond, cfg.Timeout.Duration)
+	assert.Equal(t, config.DefaultSource, sources["limit"])
+}
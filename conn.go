@@ -0,0 +1,241 @@
+package flu
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+)
+
+// TLSFiles describes the TLS client configuration stored in PEM files.
+type TLSFiles struct {
+	// CertFile is the client certificate file.
+	// May be empty.
+	CertFile string
+	// KeyFile is the client private key file.
+	// Required if CertFile is set.
+	KeyFile string
+	// CAFile is the file with CA certificates used for server verification.
+	// System CA pool is used if empty.
+	CAFile string
+	// ServerName is the expected server name.
+	// It is derived from the dialed address if empty.
+	ServerName string
+}
+
+// Config loads the files and creates a tls.Config.
+func (f TLSFiles) Config() (*tls.Config, error) {
+	config := &tls.Config{ServerName: f.ServerName}
+	if f.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(f.CertFile, f.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if f.CAFile != "" {
+		data, err := ioutil.ReadFile(f.CAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, errors.New("no certificates found in " + f.CAFile)
+		}
+
+		config.RootCAs = pool
+	}
+
+	return config, nil
+}
+
+// packetWriter buffers the data and sends it in datagrams
+// of at most max bytes. Writes are split at line boundaries if possible.
+type packetWriter struct {
+	conn net.Conn
+	buf  []byte
+	max  int
+}
+
+func (w *packetWriter) Write(data []byte) (int, error) {
+	written := 0
+	for len(data) > 0 {
+		free := w.max - len(w.buf)
+		if len(data) <= free {
+			w.buf = append(w.buf, data...)
+			return written + len(data), nil
+		}
+
+		n := bytes.LastIndexByte(data[:free], '\n') + 1
+		if n == 0 {
+			if len(w.buf) > 0 {
+				if err := w.Flush(); err != nil {
+					return written, err
+				}
+
+				continue
+			}
+
+			n = free
+		}
+
+		w.buf = append(w.buf, data[:n]...)
+		if err := w.Flush(); err != nil {
+			return written, err
+		}
+
+		data = data[n:]
+		written += n
+	}
+
+	return written, nil
+}
+
+// Flush sends the buffered data as a single datagram.
+func (w *packetWriter) Flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+
+	_, err := w.conn.Write(w.buf)
+	w.buf = w.buf[:0]
+	return err
+}
+
+func (w *packetWriter) Close() error {
+	return w.CloseWithError(nil)
+}
+
+func (w *packetWriter) CloseWithError(err error) error {
+	if err == nil {
+		err = w.Flush()
+	}
+
+	if closeErr := w.conn.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// PersistentConn is the Output which reuses a single Conn connection
+// across Writer calls. The connection is dialed on the first write
+// and redialed after a failure (a write which failed before sending anything
+// is retried once on a new connection).
+// Writers are exclusive: Writer blocks until the previous io.Writer is closed.
+// Closing the io.Writer does not close the connection, use Close for that.
+type PersistentConn struct {
+	// Conn is used for dialing.
+	Conn Conn
+
+	conn net.Conn
+	w    io.Writer
+	mu   Mutex
+}
+
+func (p *PersistentConn) Writer() (io.Writer, error) {
+	return &persistentWriter{p: p, unlocker: p.mu.Lock()}, nil
+}
+
+// Close closes the current connection (if any).
+func (p *PersistentConn) Close() error {
+	defer p.mu.Lock().Unlock()
+	return p.drop()
+}
+
+func (p *PersistentConn) dial() error {
+	conn, err := p.Conn.Dial()
+	if err != nil {
+		return err
+	}
+
+	p.conn, p.w = conn, p.Conn.writer(conn)
+	return nil
+}
+
+func (p *PersistentConn) drop() error {
+	if p.conn == nil {
+		return nil
+	}
+
+	err := p.conn.Close()
+	p.conn, p.w = nil, nil
+	return err
+}
+
+func (p *PersistentConn) write(data []byte) (int, error) {
+	redialed := false
+	if p.conn == nil {
+		if err := p.dial(); err != nil {
+			return 0, err
+		}
+
+		redialed = true
+	}
+
+	n, err := p.w.Write(data)
+	if err != nil && n == 0 && !redialed {
+		_ = p.drop()
+		if err := p.dial(); err != nil {
+			return 0, err
+		}
+
+		n, err = p.w.Write(data)
+	}
+
+	if err != nil {
+		_ = p.drop()
+	}
+
+	return n, err
+}
+
+func (p *PersistentConn) flush() error {
+	if w, ok := p.w.(*packetWriter); ok {
+		if err := w.Flush(); err != nil {
+			_ = p.drop()
+			return err
+		}
+	}
+
+	return nil
+}
+
+type persistentWriter struct {
+	p        *PersistentConn
+	unlocker Unlocker
+}
+
+func (w *persistentWriter) Write(data []byte) (int, error) {
+	if w.unlocker == nil {
+		return 0, io.ErrClosedPipe
+	}
+
+	return w.p.write(data)
+}
+
+func (w *persistentWriter) Close() error {
+	return w.CloseWithError(nil)
+}
+
+func (w *persistentWriter) CloseWithError(err error) error {
+	if w.unlocker == nil {
+		return nil
+	}
+
+	defer w.unlocker.Unlock()
+	w.unlocker = nil
+	if err != nil {
+		// the stream is broken, so the connection can not be reused
+		_ = w.p.drop()
+		return nil
+	}
+
+	return w.p.flush()
+}
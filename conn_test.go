@@ -0,0 +1,146 @@
+package flu_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jfk9w-go/flu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUDP(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	defer listener.Close()
+
+	text := &flu.PlainText{Value: "first line\nsecond line\nthird\n"}
+	err = flu.EncodeTo(text, flu.UDP(listener.LocalAddr().String(), 24))
+	assert.Nil(t, err)
+
+	var packets []string
+	buf := make([]byte, 64)
+	for i := 0; i < 2; i++ {
+		n, _, err := listener.ReadFrom(buf)
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		packets = append(packets, string(buf[:n]))
+	}
+
+	assert.Equal(t, []string{"first line\nsecond line\n", "third\n"}, packets)
+}
+
+func TestUnix(t *testing.T) {
+	dir, err := flu.TempDir("flu-unix-*")
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	defer dir.Remove()
+	path := filepath.Join(dir.Path(), "sock")
+	listener, err := net.Listen("unix", path)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	defer listener.Close()
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+		data, _ := ioutil.ReadAll(conn)
+		received <- string(data)
+	}()
+
+	err = flu.EncodeTo(&flu.PlainText{Value: "hello"}, flu.Unix(path))
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", <-received)
+}
+
+func TestConn_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		_, _ = writer.Write([]byte("secure"))
+	}))
+
+	defer server.Close()
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+
+	conn := flu.TCP(server.Listener.Addr().String())
+	conn.TLS = &tls.Config{RootCAs: pool, ServerName: "example.com"}
+	c, err := conn.Dial()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	defer c.Close()
+	_, err = c.Write([]byte("GET / HTTP/1.0\r\n\r\n"))
+	assert.Nil(t, err)
+	data, err := ioutil.ReadAll(c)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasSuffix(string(data), "secure"))
+
+	conn.TLS = &tls.Config{ServerName: "example.com"}
+	_, err = conn.Dial()
+	assert.NotNil(t, err)
+}
+
+func TestPersistentConn(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	defer listener.Close()
+	conns := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				close(conns)
+				return
+			}
+
+			conns <- conn
+		}
+	}()
+
+	readLine := func(conn net.Conn, size int) string {
+		buf := make([]byte, size)
+		_, err := conn.Read(buf)
+		assert.Nil(t, err)
+		return string(buf)
+	}
+
+	out := &flu.PersistentConn{Conn: flu.TCP(listener.Addr().String())}
+	defer out.Close()
+
+	assert.Nil(t, flu.EncodeTo(&flu.PlainText{Value: "one\n"}, out))
+	first := <-conns
+	assert.Equal(t, "one\n", readLine(first, 4))
+
+	assert.Nil(t, flu.EncodeTo(&flu.PlainText{Value: "two\n"}, out))
+	assert.Equal(t, "two\n", readLine(first, 4))
+
+	assert.Nil(t, out.Close())
+	assert.Nil(t, flu.EncodeTo(&flu.PlainText{Value: "three\n"}, out))
+	second := <-conns
+	assert.Equal(t, "three\n", readLine(second, 6))
+	_ = first.Close()
+	_ = second.Close()
+}
@@ -0,0 +1,277 @@
+package flu
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+)
+
+// ContextInput interface describes an Input which can be read
+// within a context.Context.
+type ContextInput interface {
+	Input
+	// ReaderContext returns an instance of io.Reader bound to the context.
+	ReaderContext(ctx context.Context) (io.Reader, error)
+}
+
+// ContextOutput interface describes an Output which can be written
+// within a context.Context.
+type ContextOutput interface {
+	Output
+	// WriterContext returns an instance of io.Writer bound to the context.
+	WriterContext(ctx context.Context) (io.Writer, error)
+}
+
+// ContextReader opens the Input within the context.
+// If Input does not implement ContextInput, the returned io.Reader
+// is closed as soon as the context is done, interrupting in-flight reads.
+func ContextReader(ctx context.Context, in Input) (io.Reader, error) {
+	if ctx.Done() == nil {
+		return in.Reader()
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if in, ok := in.(ContextInput); ok {
+		return in.ReaderContext(ctx)
+	}
+
+	r, err := in.Reader()
+	if err != nil {
+		return nil, err
+	}
+
+	return WithContext(ctx, r).(io.Reader), nil
+}
+
+// ContextWriter opens the Output within the context.
+// If Output does not implement ContextOutput, the returned io.Writer
+// is closed as soon as the context is done, interrupting in-flight writes.
+func ContextWriter(ctx context.Context, out Output) (io.Writer, error) {
+	if ctx.Done() == nil {
+		return out.Writer()
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if out, ok := out.(ContextOutput); ok {
+		return out.WriterContext(ctx)
+	}
+
+	w, err := out.Writer()
+	if err != nil {
+		return nil, err
+	}
+
+	return WithContext(ctx, w).(io.Writer), nil
+}
+
+// WithContext binds the provided io.Reader or io.Writer to the context.
+// Reads and writes fail with the context error once the context is done.
+// If the value is an io.Closer, it is closed when the context is done
+// so that blocked reads and writes are interrupted.
+// The value is returned as is if the context can never be done.
+func WithContext(ctx context.Context, value interface{}) interface{} {
+	if ctx.Done() == nil {
+		return value
+	}
+
+	c := newContextCloser(ctx, value)
+	r, isReader := value.(io.Reader)
+	w, isWriter := value.(io.Writer)
+	switch {
+	case isReader && isWriter:
+		return &contextReadWriter{contextReader{r, c}, contextWriter{w, c}}
+	case isReader:
+		return contextReader{r, c}
+	case isWriter:
+		return contextWriter{w, c}
+	default:
+		return c
+	}
+}
+
+type contextCloser struct {
+	ctx   context.Context
+	value interface{}
+	stop  chan struct{}
+	once  sync.Once
+	err   error
+}
+
+func newContextCloser(ctx context.Context, value interface{}) *contextCloser {
+	c := &contextCloser{ctx: ctx, value: value}
+	if _, ok := value.(io.Closer); ok {
+		c.stop = make(chan struct{})
+		go c.watch()
+	}
+
+	return c
+}
+
+func (c *contextCloser) watch() {
+	select {
+	case <-c.ctx.Done():
+		_ = c.closeWithError(c.ctx.Err())
+	case <-c.stop:
+	}
+}
+
+func (c *contextCloser) closeWithError(err error) error {
+	c.once.Do(func() {
+		if c.stop != nil {
+			close(c.stop)
+		}
+
+		c.err = CloseWithError(c.value, err)
+	})
+
+	return c.err
+}
+
+func (c *contextCloser) wrap(err error) error {
+	if err != nil && err != io.EOF {
+		if ctxErr := c.ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+	}
+
+	return err
+}
+
+func (c *contextCloser) Close() error {
+	return c.CloseWithError(nil)
+}
+
+func (c *contextCloser) CloseWithError(err error) error {
+	return c.closeWithError(err)
+}
+
+type contextReader struct {
+	r io.Reader
+	*contextCloser
+}
+
+func (r contextReader) Read(data []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := r.r.Read(data)
+	return n, r.wrap(err)
+}
+
+type contextWriter struct {
+	w io.Writer
+	*contextCloser
+}
+
+func (w contextWriter) Write(data []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := w.w.Write(data)
+	return n, w.wrap(err)
+}
+
+type contextReadWriter struct {
+	contextReader
+	contextWriter
+}
+
+func (rw *contextReadWriter) Close() error {
+	return rw.contextReader.Close()
+}
+
+func (rw *contextReadWriter) CloseWithError(err error) error {
+	return rw.contextReader.CloseWithError(err)
+}
+
+type contextConn struct {
+	net.Conn
+	c *contextCloser
+}
+
+func (c contextConn) Read(data []byte) (int, error) {
+	return contextReader{c.Conn, c.c}.Read(data)
+}
+
+func (c contextConn) Write(data []byte) (int, error) {
+	return contextWriter{c.Conn, c.c}.Write(data)
+}
+
+func (c contextConn) Close() error {
+	return c.c.Close()
+}
+
+func (c contextConn) CloseWithError(err error) error {
+	return c.c.CloseWithError(err)
+}
+
+// EncodeToContext encodes the provided EncoderTo to Output within the context.
+// It closes the io.Writer instance if necessary.
+// If encoding fails, the io.Writer is closed with the error (see CloseWithError).
+func EncodeToContext(ctx context.Context, encoder EncoderTo, out Output) error {
+	w, err := ContextWriter(ctx, out)
+	if err != nil {
+		return err
+	}
+
+	if err := encoder.EncodeTo(w); err != nil {
+		_ = CloseWithError(w, err)
+		return err
+	}
+
+	return Close(w)
+}
+
+// DecodeFromContext decodes the provided DecoderFrom from Input within the context.
+// It closes the io.Reader instance if necessary.
+func DecodeFromContext(ctx context.Context, in Input, decoder DecoderFrom) error {
+	r, err := ContextReader(ctx, in)
+	if err != nil {
+		return err
+	}
+
+	if err := decoder.DecodeFrom(r); err != nil {
+		_ = CloseWithError(r, err)
+		return err
+	}
+
+	return Close(r)
+}
+
+// CopyContext copies the Input to the Output within the context.
+func CopyContext(ctx context.Context, in Input, out Output) (written int64, err error) {
+	r, err := ContextReader(ctx, in)
+	if err != nil {
+		return
+	}
+
+	w, err := ContextWriter(ctx, out)
+	if err != nil {
+		_ = CloseWithError(r, err)
+		return
+	}
+
+	written, err = io.Copy(w, r)
+	if err != nil {
+		_ = CloseWithError(w, err)
+		_ = CloseWithError(r, err)
+		return
+	}
+
+	if err = Close(w); err != nil {
+		_ = CloseWithError(r, err)
+		return
+	}
+
+	err = Close(r)
+	return
+}
@@ -0,0 +1,77 @@
+package flu_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jfk9w-go/flu"
+	"github.com/stretchr/testify/assert"
+)
+
+type encoderFunc func(w io.Writer) error
+
+func (fun encoderFunc) EncodeTo(w io.Writer) error {
+	return fun(w)
+}
+
+func TestCopyContext_PipeInput(t *testing.T) {
+	done := make(chan error, 1)
+	in := flu.PipeInput(encoderFunc(func(w io.Writer) error {
+		data := make([]byte, 1024)
+		for {
+			if _, err := w.Write(data); err != nil {
+				done <- err
+				return err
+			}
+		}
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := flu.CopyContext(ctx, in, flu.IO{W: ioutil.Discard})
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	select {
+	case err := <-done:
+		assert.Equal(t, context.DeadlineExceeded, err)
+	case <-time.After(time.Second):
+		t.Fatal("encoder goroutine has not been stopped")
+	}
+}
+
+func TestDecodeFromContext_Conn(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+		time.Sleep(time.Second)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	conn := flu.Conn{Network: "tcp", Address: listener.Addr().String()}
+	err = flu.DecodeFromContext(ctx, conn, new(flu.PlainText))
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestPipeOutput(t *testing.T) {
+	text := new(flu.PlainText)
+	err := flu.EncodeTo(&flu.PlainText{Value: "test"}, flu.PipeOutput(text))
+	assert.Nil(t, err)
+	assert.Equal(t, "test", text.Value)
+}
@@ -0,0 +1,306 @@
+package flu
+
+import (
+	"encoding"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// CSV encodes/decodes a sequence of structs using CSV format.
+// Rows are processed one by one without buffering the whole stream.
+//
+// Struct fields are mapped to columns by `csv:"column"` tags.
+// Untagged exported fields are mapped by field name, "-" skips the field.
+// Field values are converted using FromString / String methods
+// (like serde.Time, serde.Duration and serde.Size),
+// encoding.TextUnmarshaler / encoding.TextMarshaler
+// or as plain strings, booleans and numbers.
+//
+// Value may be of the same types as in JSONLines
+// with struct (or pointer to struct) elements.
+type CSV struct {
+	Value interface{}
+	// Comma is the field delimiter. Zero value means ','.
+	Comma rune
+	// NoHeader disables writing and reading the header row.
+	// Columns are mapped to struct fields in declaration order then.
+	NoHeader bool
+}
+
+func (c CSV) EncodeTo(w io.Writer) error {
+	valueType := reflect.TypeOf(c.Value)
+	if valueType != nil && valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+	}
+
+	if valueType == nil {
+		return fmt.Errorf("unsupported value type: %T", c.Value)
+	}
+
+	switch valueType.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Chan:
+	default:
+		return fmt.Errorf("unsupported value type: %T (expected slice, array or channel)", c.Value)
+	}
+
+	fields, err := csvFields(valueType.Elem())
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if c.Comma != 0 {
+		writer.Comma = c.Comma
+	}
+
+	record := make([]string, len(fields))
+	if !c.NoHeader {
+		for i, field := range fields {
+			record[i] = field.column
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	if err := forEachValue(c.Value, func(value reflect.Value) error {
+		value = reflect.Indirect(value)
+		for i, field := range fields {
+			if record[i], err = formatString(value.Field(field.index)); err != nil {
+				return fmt.Errorf("format %s: %w", field.column, err)
+			}
+		}
+
+		return writer.Write(record)
+	}); err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (c CSV) DecodeFrom(r io.Reader) error {
+	sink, err := newValueSink(c.Value)
+	if err != nil {
+		return err
+	}
+
+	defer sink.Close()
+	fields, err := csvFields(sink.elemType)
+	if err != nil {
+		return err
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	if c.Comma != 0 {
+		reader.Comma = c.Comma
+	}
+
+	columns := fields
+	if !c.NoHeader {
+		header, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		byColumn := make(map[string]csvField, len(fields))
+		for _, field := range fields {
+			byColumn[field.column] = field
+		}
+
+		columns = make([]csvField, len(header))
+		for i, column := range header {
+			if field, ok := byColumn[column]; ok {
+				columns[i] = field
+			} else {
+				columns[i] = csvField{index: -1}
+			}
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		ptr := sink.New()
+		value := ptr.Elem()
+		if value.Kind() == reflect.Ptr {
+			value.Set(reflect.New(value.Type().Elem()))
+			value = value.Elem()
+		}
+
+		for i, str := range record {
+			if i >= len(columns) || columns[i].index < 0 {
+				continue
+			}
+
+			if err := parseString(value.Field(columns[i].index), str); err != nil {
+				line, column := reader.FieldPos(i)
+				return &csv.ParseError{StartLine: line, Line: line, Column: column, Err: err}
+			}
+		}
+
+		if err := sink.Put(ptr); err != nil {
+			return err
+		}
+	}
+}
+
+func (c CSV) ContentType() string {
+	return "text/csv"
+}
+
+type csvField struct {
+	column string
+	index  int
+}
+
+func csvFields(elemType reflect.Type) ([]csvField, error) {
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+
+	if elemType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unsupported element type: %s (expected struct)", elemType)
+	}
+
+	fields := make([]csvField, 0, elemType.NumField())
+	for i := 0; i < elemType.NumField(); i++ {
+		field := elemType.Field(i)
+		column, tagged := field.Tag.Lookup("csv")
+		if column == "-" || field.PkgPath != "" || field.Anonymous && !tagged {
+			continue
+		}
+
+		if column == "" {
+			column = field.Name
+		}
+
+		fields = append(fields, csvField{column: column, index: i})
+	}
+
+	return fields, nil
+}
+
+var (
+	stringerType        = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	stringParserType    = reflect.TypeOf((*interface{ FromString(string) error })(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// formatString converts the value to string.
+func formatString(value reflect.Value) (string, error) {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return "", nil
+		}
+
+		value = value.Elem()
+	}
+
+	valueType := value.Type()
+	switch {
+	case valueType.Implements(stringerType) && reflect.PtrTo(valueType).Implements(stringParserType):
+		return value.Interface().(fmt.Stringer).String(), nil
+	case valueType.Implements(textMarshalerType):
+		text, err := value.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, valueType.Bits()), nil
+	default:
+		return "", fmt.Errorf("unsupported type: %s", valueType)
+	}
+}
+
+// parseString parses the string into the addressable value.
+// Empty strings leave non-string values untouched.
+func parseString(value reflect.Value, str string) error {
+	if value.Kind() == reflect.Ptr {
+		if str == "" {
+			return nil
+		}
+
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+
+		value = value.Elem()
+	}
+
+	ptr := value.Addr().Interface()
+	if parser, ok := ptr.(interface{ FromString(string) error }); ok {
+		if str == "" {
+			return nil
+		}
+
+		return parser.FromString(str)
+	} else if unmarshaler, ok := ptr.(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(str))
+	}
+
+	if value.Kind() == reflect.String {
+		value.SetString(str)
+		return nil
+	} else if str == "" {
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(str)
+		if err != nil {
+			return err
+		}
+
+		value.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(str, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		value.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(str, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		value.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(str, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		value.SetFloat(parsed)
+	default:
+		return fmt.Errorf("unsupported type: %s", value.Type())
+	}
+
+	return nil
+}
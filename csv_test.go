@@ -0,0 +1,74 @@
+package flu_test
+
+import (
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/jfk9w-go/flu"
+	"github.com/jfk9w-go/flu/serde"
+	"github.com/stretchr/testify/assert"
+)
+
+type csvReportRow struct {
+	Name     string         `csv:"name"`
+	Count    int            `csv:"count"`
+	Ratio    float64        `csv:"ratio"`
+	Started  serde.Time     `csv:"started"`
+	Elapsed  serde.Duration `csv:"elapsed"`
+	Size     serde.Size     `csv:"size"`
+	Internal string         `csv:"-"`
+}
+
+func TestCSV(t *testing.T) {
+	rows := []csvReportRow{
+		{
+			Name:    "first",
+			Count:   1,
+			Ratio:   0.5,
+			Started: serde.Time{Time: time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC)},
+			Elapsed: serde.Duration{Duration: time.Minute},
+			Size:    serde.Size{Bytes: 2 << 20},
+		},
+		{
+			Name:  "second, quoted",
+			Count: 2,
+			Size:  serde.Size{Bytes: 1536},
+		},
+	}
+
+	buf := new(flu.ByteBuffer)
+	err := flu.EncodeTo(flu.CSV{Value: rows, Comma: ';'}, buf)
+	assert.Nil(t, err)
+	assert.Equal(t, ""+
+		"name;count;ratio;started;elapsed;size\n"+
+		"first;1;0.5;2021-05-01 10:00:00;1m0s;2Mb\n"+
+		"second, quoted;2;0;0001-01-01 00:00:00;0s;1536b\n", buf.Unmask().String())
+
+	var decoded []csvReportRow
+	err = flu.DecodeFrom(buf.Bytes(), flu.CSV{Value: &decoded, Comma: ';'})
+	assert.Nil(t, err)
+	assert.Equal(t, rows, decoded)
+}
+
+func TestCSV_DecodeFrom_Header(t *testing.T) {
+	input := flu.Bytes("count,unknown,name\n5,x,test\n7,y,\n")
+	var names []string
+	err := flu.DecodeFrom(input, flu.CSV{Value: func(row *csvReportRow) error {
+		names = append(names, row.Name)
+		return nil
+	}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"test", ""}, names)
+}
+
+func TestCSV_DecodeFrom_Error(t *testing.T) {
+	input := flu.Bytes("name,count\ntest,abc\n")
+	var rows []csvReportRow
+	err := flu.DecodeFrom(input, flu.CSV{Value: &rows})
+	if assert.IsType(t, new(csv.ParseError), err) {
+		assert.Equal(t, 2, err.(*csv.ParseError).Line)
+		assert.Equal(t, 6, err.(*csv.ParseError).Column)
+	}
+}
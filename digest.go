@@ -0,0 +1,201 @@
+package flu
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+)
+
+// Hash functions for Digest.
+var (
+	MD5    = md5.New
+	SHA1   = sha1.New
+	SHA256 = sha256.New
+	CRC32  = func() hash.Hash { return crc32.NewIEEE() }
+)
+
+// ChecksumError is returned when the computed digest
+// does not match the expected one.
+type ChecksumError struct {
+	// Expected is the expected digest as provided.
+	Expected string
+	// Actual is the hex-encoded computed digest.
+	Actual string
+}
+
+func (e ChecksumError) Error() string {
+	return fmt.Sprintf("checksum mismatch: expected %s, got %s", e.Expected, e.Actual)
+}
+
+// Digest is the Input / Output wrapper which computes the digest
+// of the data flowing through it.
+// The digest is available after the io.Reader has been read to the end
+// or closed (the rest of the input is read on Close) or the io.Writer has been closed.
+type Digest struct {
+	// In is the underlying Input.
+	In Input
+	// Out is the underlying Output.
+	Out Output
+	// Hash creates a new hash.Hash (like MD5).
+	// Defaults to SHA256.
+	Hash func() hash.Hash
+	// Expected is the expected digest in hex or base64 encoding.
+	// If set, the final Read or Close (for Input) or Close (for Output)
+	// fails with ChecksumError on mismatch.
+	Expected string
+
+	sum []byte
+}
+
+func (d *Digest) Reader() (io.Reader, error) {
+	r, err := d.In.Reader()
+	if err != nil {
+		return nil, err
+	}
+
+	d.sum = nil
+	return &digestReader{r: r, hash: d.newHash(), digest: d}, nil
+}
+
+func (d *Digest) Writer() (io.Writer, error) {
+	w, err := d.Out.Writer()
+	if err != nil {
+		return nil, err
+	}
+
+	d.sum = nil
+	return &digestWriter{w: w, hash: d.newHash(), digest: d}, nil
+}
+
+func (d *Digest) newHash() hash.Hash {
+	if d.Hash == nil {
+		return SHA256()
+	}
+
+	return d.Hash()
+}
+
+// Sum returns the computed digest.
+func (d *Digest) Sum() []byte {
+	return d.sum
+}
+
+// Hex returns the hex-encoded computed digest.
+func (d *Digest) Hex() string {
+	return hex.EncodeToString(d.sum)
+}
+
+// Base64 returns the base64-encoded computed digest.
+func (d *Digest) Base64() string {
+	return base64.StdEncoding.EncodeToString(d.sum)
+}
+
+func (d *Digest) complete(h hash.Hash) error {
+	d.sum = h.Sum(nil)
+	if d.Expected == "" {
+		return nil
+	}
+
+	if !bytes.Equal(decodeDigest(d.Expected, len(d.sum)), d.sum) {
+		return ChecksumError{Expected: d.Expected, Actual: d.Hex()}
+	}
+
+	return nil
+}
+
+func decodeDigest(value string, size int) []byte {
+	if len(value) == 2*size {
+		if sum, err := hex.DecodeString(value); err == nil {
+			return sum
+		}
+	}
+
+	for _, encoding := range []*base64.Encoding{
+		base64.StdEncoding, base64.URLEncoding,
+		base64.RawStdEncoding, base64.RawURLEncoding,
+	} {
+		if sum, err := encoding.DecodeString(value); err == nil && len(sum) == size {
+			return sum
+		}
+	}
+
+	return nil
+}
+
+type digestReader struct {
+	r      io.Reader
+	hash   hash.Hash
+	digest *Digest
+	done   bool
+}
+
+func (dr *digestReader) Read(data []byte) (int, error) {
+	n, err := dr.r.Read(data)
+	dr.hash.Write(data[:n])
+	if err == io.EOF && !dr.done {
+		dr.done = true
+		if err := dr.digest.complete(dr.hash); err != nil {
+			return n, err
+		}
+	}
+
+	return n, err
+}
+
+func (dr *digestReader) Close() error {
+	return dr.CloseWithError(nil)
+}
+
+// CloseWithError reads the rest of the input through the hash
+// and verifies the digest if err is nil (for example,
+// when a decoder stops before EOF).
+func (dr *digestReader) CloseWithError(err error) error {
+	if err == nil && !dr.done {
+		dr.done = true
+		if _, err = io.Copy(dr.hash, dr.r); err == nil {
+			err = dr.digest.complete(dr.hash)
+		}
+
+		if err != nil {
+			_ = CloseWithError(dr.r, err)
+			return err
+		}
+	}
+
+	return CloseWithError(dr.r, err)
+}
+
+type digestWriter struct {
+	w      io.Writer
+	hash   hash.Hash
+	digest *Digest
+}
+
+func (dw *digestWriter) Write(data []byte) (int, error) {
+	n, err := dw.w.Write(data)
+	dw.hash.Write(data[:n])
+	return n, err
+}
+
+func (dw *digestWriter) Close() error {
+	return dw.CloseWithError(nil)
+}
+
+func (dw *digestWriter) CloseWithError(err error) error {
+	if err == nil {
+		err = dw.digest.complete(dw.hash)
+		if err != nil {
+			_ = CloseWithError(dw.w, err)
+			return err
+		}
+	}
+
+	return CloseWithError(dw.w, err)
+}
@@ -0,0 +1,62 @@
+package flu_test
+
+import (
+	"testing"
+
+	"github.com/jfk9w-go/flu"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	digestTestData   = "hello world"
+	digestTestSHA256 = "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+)
+
+func TestDigest_Reader(t *testing.T) {
+	in := &flu.Digest{In: flu.Bytes(digestTestData), Hash: flu.SHA256, Expected: digestTestSHA256}
+	out := new(flu.ByteBuffer)
+	_, err := flu.Copy(in, out)
+	assert.Nil(t, err)
+	assert.Equal(t, digestTestSHA256, in.Hex())
+	assert.Equal(t, digestTestData, out.Unmask().String())
+
+	in.Expected = "uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek="
+	_, err = flu.Copy(in, out)
+	assert.Nil(t, err)
+
+	in.Expected = "0000"
+	_, err = flu.Copy(in, out)
+	assert.Equal(t, flu.ChecksumError{Expected: "0000", Actual: digestTestSHA256}, err)
+}
+
+func TestDigest_Writer(t *testing.T) {
+	out := &flu.Digest{Out: new(flu.ByteBuffer), Hash: flu.CRC32}
+	err := flu.EncodeTo(&flu.PlainText{Value: digestTestData}, out)
+	assert.Nil(t, err)
+	assert.Equal(t, "0d4a1185", out.Hex())
+
+	out.Expected = "00000000"
+	err = flu.EncodeTo(&flu.PlainText{Value: digestTestData}, out)
+	assert.Equal(t, flu.ChecksumError{Expected: "00000000", Actual: "0d4a1185"}, err)
+}
+
+func TestDigest_DefaultHash(t *testing.T) {
+	out := &flu.Digest{Out: new(flu.ByteBuffer)}
+	err := flu.EncodeTo(&flu.PlainText{Value: digestTestData}, out)
+	assert.Nil(t, err)
+	assert.Equal(t, digestTestSHA256, out.Hex())
+}
+
+func TestDigest_ReaderClose(t *testing.T) {
+	data := `{"a":1}` + "\n\n"
+	in := &flu.Digest{In: flu.Bytes(data), Expected: "0000"}
+	value := make(map[string]int)
+	err := flu.DecodeFrom(in, flu.JSON{Value: &value})
+	assert.IsType(t, flu.ChecksumError{}, err)
+	assert.Equal(t, map[string]int{"a": 1}, value)
+
+	in.Expected = in.Hex()
+	assert.NotEmpty(t, in.Expected)
+	err = flu.DecodeFrom(in, flu.JSON{Value: &value})
+	assert.Nil(t, err)
+}
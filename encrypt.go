@@ -0,0 +1,420 @@
+package flu
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Cipher is the authenticated encryption algorithm used by Encrypted.
+type Cipher byte
+
+const (
+	// AESGCM is AES in Galois/Counter Mode.
+	// The key must be 16, 24 or 32 bytes long.
+	AESGCM Cipher = iota + 1
+	// ChaCha20Poly1305 is ChaCha20-Poly1305 (RFC 8439).
+	// The key must be 32 bytes long.
+	ChaCha20Poly1305
+)
+
+func (c Cipher) String() string {
+	switch c {
+	case AESGCM:
+		return "AES-GCM"
+	case ChaCha20Poly1305:
+		return "ChaCha20-Poly1305"
+	default:
+		return fmt.Sprintf("Cipher(%d)", byte(c))
+	}
+}
+
+func (c Cipher) aead(key []byte) (cipher.AEAD, error) {
+	switch c {
+	case AESGCM:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+
+		return cipher.NewGCM(block)
+	case ChaCha20Poly1305:
+		return chacha20poly1305.New(key)
+	default:
+		return nil, fmt.Errorf("unsupported cipher: %s", c)
+	}
+}
+
+// DefaultEncryptionChunkSize is the plaintext chunk size used by Encrypted
+// when ChunkSize is not set.
+const DefaultEncryptionChunkSize = 64 << 10
+
+const (
+	encryptionMagic        = "FLUE"
+	encryptionVersion      = 1
+	encryptionSaltSize     = 16
+	encryptionPrefixSize   = 7
+	encryptionMaxChunkSize = 16 << 20
+)
+
+// AuthenticationError is returned when an encrypted chunk
+// fails authentication (it has been tampered with, truncated,
+// reordered or the key is wrong).
+type AuthenticationError struct {
+	// Chunk is the 0-based chunk number.
+	Chunk uint32
+}
+
+func (e AuthenticationError) Error() string {
+	return fmt.Sprintf("chunk %d authentication failed", e.Chunk)
+}
+
+// Encrypted is the authenticated encryption Input / Output wrapper.
+// The stream is split into chunks which are sealed separately
+// with the chunk counter and the final chunk flag bound to the nonce,
+// so chunk reordering and stream truncation are detected.
+// Each chunk is authenticated before its plaintext is returned by the io.Reader.
+// The header written before the first chunk holds the cipher,
+// the chunk size and the passphrase salt, so only the key or passphrase
+// is required for reading.
+type Encrypted struct {
+	// In is the underlying Input.
+	In Input
+	// Out is the underlying Output.
+	Out Output
+	// Cipher is the Cipher used when writing.
+	// Defaults to AESGCM.
+	Cipher Cipher
+	// Key is the encryption key.
+	// Either Key or Passphrase must be set.
+	Key []byte
+	// Passphrase is used to derive the key with scrypt and a random salt.
+	Passphrase string
+	// ChunkSize is the plaintext chunk size used when writing.
+	// Defaults to DefaultEncryptionChunkSize.
+	ChunkSize int
+}
+
+func (e Encrypted) key(salt []byte) ([]byte, error) {
+	if len(salt) > 0 {
+		if e.Passphrase == "" {
+			return nil, errors.New("passphrase is required")
+		}
+
+		return scrypt.Key([]byte(e.Passphrase), salt, 1<<15, 8, 1, 32)
+	}
+
+	if len(e.Key) == 0 {
+		return nil, errors.New("key is required")
+	}
+
+	return e.Key, nil
+}
+
+func (e Encrypted) Reader() (io.Reader, error) {
+	r, err := e.In.Reader()
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(r)
+	header, c, chunkSize, salt, err := readEncryptionHeader(br)
+	if err != nil {
+		_ = CloseWithError(r, err)
+		return nil, err
+	}
+
+	key, err := e.key(salt)
+	if err != nil {
+		_ = CloseWithError(r, err)
+		return nil, err
+	}
+
+	aead, err := c.aead(key)
+	if err != nil {
+		_ = CloseWithError(r, err)
+		return nil, err
+	}
+
+	return &decryptReader{
+		r:      br,
+		closer: r,
+		chunks: chunkNonce{aead: aead, header: header},
+		sealed: make([]byte, chunkSize+aead.Overhead()),
+	}, nil
+}
+
+func readEncryptionHeader(r io.Reader) (header []byte, c Cipher, chunkSize int, salt []byte, err error) {
+	header = make([]byte, len(encryptionMagic)+7)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return
+	}
+
+	if string(header[:len(encryptionMagic)]) != encryptionMagic {
+		err = errors.New("invalid encryption header")
+		return
+	}
+
+	fields := header[len(encryptionMagic):]
+	if fields[0] != encryptionVersion {
+		err = fmt.Errorf("unsupported encryption version: %d", fields[0])
+		return
+	}
+
+	c = Cipher(fields[1])
+	chunkSize = int(binary.BigEndian.Uint32(fields[2:6]))
+	if chunkSize <= 0 || chunkSize > encryptionMaxChunkSize {
+		err = fmt.Errorf("invalid encryption chunk size: %d", chunkSize)
+		return
+	}
+
+	tail := make([]byte, int(fields[6])+encryptionPrefixSize)
+	if _, err = io.ReadFull(r, tail); err != nil {
+		return
+	}
+
+	header = append(header, tail...)
+	salt = tail[:fields[6]]
+	return
+}
+
+func (e Encrypted) Writer() (io.Writer, error) {
+	c := e.Cipher
+	if c == 0 {
+		c = AESGCM
+	}
+
+	chunkSize := e.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultEncryptionChunkSize
+	} else if chunkSize > encryptionMaxChunkSize {
+		return nil, fmt.Errorf("invalid encryption chunk size: %d", chunkSize)
+	}
+
+	var salt []byte
+	if e.Passphrase != "" {
+		salt = make([]byte, encryptionSaltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+	}
+
+	key, err := e.key(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := c.aead(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := new(bytes.Buffer)
+	header.WriteString(encryptionMagic)
+	header.WriteByte(encryptionVersion)
+	header.WriteByte(byte(c))
+	_ = binary.Write(header, binary.BigEndian, uint32(chunkSize))
+	header.WriteByte(byte(len(salt)))
+	header.Write(salt)
+	prefix := make([]byte, encryptionPrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, err
+	}
+
+	header.Write(prefix)
+
+	w, err := e.Out.Writer()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(header.Bytes()); err != nil {
+		_ = CloseWithError(w, err)
+		return nil, err
+	}
+
+	return &encryptWriter{
+		w:      w,
+		chunks: chunkNonce{aead: aead, header: header.Bytes()},
+		plain:  make([]byte, 0, chunkSize),
+	}, nil
+}
+
+// chunkNonce seals and opens the stream chunks.
+// The nonce is the random prefix from the header followed by
+// the big-endian chunk counter and the final chunk flag.
+// The header is authenticated with each chunk as additional data.
+type chunkNonce struct {
+	aead    cipher.AEAD
+	header  []byte
+	counter uint32
+	nonce   []byte
+}
+
+func (c *chunkNonce) next(last bool) ([]byte, error) {
+	if c.nonce == nil {
+		c.nonce = make([]byte, c.aead.NonceSize())
+		copy(c.nonce, c.header[len(c.header)-encryptionPrefixSize:])
+	} else if c.counter == 0 {
+		return nil, errors.New("too many encrypted chunks")
+	}
+
+	binary.BigEndian.PutUint32(c.nonce[encryptionPrefixSize:], c.counter)
+	c.nonce[len(c.nonce)-1] = 0
+	if last {
+		c.nonce[len(c.nonce)-1] = 1
+	}
+
+	c.counter++
+	return c.nonce, nil
+}
+
+type encryptWriter struct {
+	w      io.Writer
+	chunks chunkNonce
+	plain  []byte
+	sealed []byte
+	closed bool
+}
+
+func (ew *encryptWriter) Write(data []byte) (int, error) {
+	if ew.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	written := 0
+	for len(data) > 0 {
+		if len(ew.plain) == cap(ew.plain) {
+			if err := ew.seal(false); err != nil {
+				return written, err
+			}
+		}
+
+		n := cap(ew.plain) - len(ew.plain)
+		if n > len(data) {
+			n = len(data)
+		}
+
+		ew.plain = append(ew.plain, data[:n]...)
+		data = data[n:]
+		written += n
+	}
+
+	return written, nil
+}
+
+func (ew *encryptWriter) seal(last bool) error {
+	nonce, err := ew.chunks.next(last)
+	if err != nil {
+		return err
+	}
+
+	ew.sealed = ew.chunks.aead.Seal(ew.sealed[:0], nonce, ew.plain, ew.chunks.header)
+	ew.plain = ew.plain[:0]
+	_, err = ew.w.Write(ew.sealed)
+	return err
+}
+
+func (ew *encryptWriter) Close() error {
+	return ew.CloseWithError(nil)
+}
+
+// CloseWithError seals the final chunk if err is nil.
+// Otherwise, the final chunk is not written, so the output
+// can not be successfully decrypted.
+func (ew *encryptWriter) CloseWithError(err error) error {
+	if ew.closed {
+		return nil
+	}
+
+	ew.closed = true
+	if err == nil {
+		err = ew.seal(true)
+		if err != nil {
+			_ = CloseWithError(ew.w, err)
+			return err
+		}
+	}
+
+	return CloseWithError(ew.w, err)
+}
+
+type decryptReader struct {
+	r      *bufio.Reader
+	closer interface{}
+	chunks chunkNonce
+	sealed []byte
+	buf    []byte
+	plain  []byte
+	done   bool
+	err    error
+}
+
+func (dr *decryptReader) Read(data []byte) (int, error) {
+	for len(dr.plain) == 0 {
+		if dr.err != nil {
+			return 0, dr.err
+		}
+
+		if dr.done {
+			return 0, io.EOF
+		}
+
+		dr.err = dr.open()
+	}
+
+	n := copy(data, dr.plain)
+	dr.plain = dr.plain[n:]
+	return n, nil
+}
+
+func (dr *decryptReader) open() error {
+	n, err := io.ReadFull(dr.r, dr.sealed)
+	last := false
+	switch err {
+	case nil:
+		if _, err := dr.r.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	case io.ErrUnexpectedEOF:
+		last = true
+	case io.EOF:
+		// the final chunk is missing
+		return AuthenticationError{Chunk: dr.chunks.counter}
+	default:
+		return err
+	}
+
+	chunk := dr.chunks.counter
+	nonce, err := dr.chunks.next(last)
+	if err != nil {
+		return err
+	}
+
+	plain, err := dr.chunks.aead.Open(dr.buf[:0], nonce, dr.sealed[:n], dr.chunks.header)
+	if err != nil {
+		return AuthenticationError{Chunk: chunk}
+	}
+
+	dr.buf, dr.plain, dr.done = plain, plain, last
+	return nil
+}
+
+func (dr *decryptReader) Close() error {
+	return Close(dr.closer)
+}
+
+func (dr *decryptReader) CloseWithError(err error) error {
+	return CloseWithError(dr.closer, err)
+}
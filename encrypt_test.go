@@ -0,0 +1,94 @@
+package flu_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jfk9w-go/flu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncrypted(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	text := strings.Repeat("secret data\n", 100)
+	for _, tc := range []struct {
+		name      string
+		encrypted flu.Encrypted
+	}{
+		{"aes-gcm key", flu.Encrypted{Key: key, ChunkSize: 64}},
+		{"chacha20-poly1305 key", flu.Encrypted{Cipher: flu.ChaCha20Poly1305, Key: key, ChunkSize: 100}},
+		{"passphrase", flu.Encrypted{Passphrase: "passphrase"}},
+		{"exact chunk", flu.Encrypted{Key: key, ChunkSize: len(text)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := new(flu.ByteBuffer)
+			encrypted := tc.encrypted
+			encrypted.Out = buf
+			err := flu.EncodeTo(&flu.PlainText{Value: text}, encrypted)
+			assert.Nil(t, err)
+			assert.False(t, bytes.Contains(buf.Bytes(), []byte("secret")))
+
+			decrypted := new(flu.PlainText)
+			encrypted.In = buf.Bytes()
+			err = flu.DecodeFrom(encrypted, decrypted)
+			assert.Nil(t, err)
+			assert.Equal(t, text, decrypted.Value)
+		})
+	}
+}
+
+func TestEncrypted_File(t *testing.T) {
+	dir, err := flu.TempDir("flu-encrypted-*")
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	defer dir.Remove()
+	type Session struct {
+		Token string `json:"token"`
+	}
+
+	file := dir.Join("session.json.enc")
+	err = flu.EncodeTo(flu.JSON{Value: Session{Token: "token"}}, flu.Encrypted{Out: file, Passphrase: "pass"})
+	assert.Nil(t, err)
+
+	session := new(Session)
+	err = flu.DecodeFrom(flu.Encrypted{In: file, Passphrase: "pass"}, flu.JSON{Value: session})
+	assert.Nil(t, err)
+	assert.Equal(t, "token", session.Token)
+
+	err = flu.DecodeFrom(flu.Encrypted{In: file, Passphrase: "wrong"}, flu.JSON{Value: session})
+	assert.Equal(t, flu.AuthenticationError{Chunk: 0}, err)
+
+	_, err = os.Stat(file.Path())
+	assert.Nil(t, err)
+}
+
+func TestEncrypted_Tampering(t *testing.T) {
+	key := bytes.Repeat([]byte{2}, 16)
+	buf := new(flu.ByteBuffer)
+	err := flu.EncodeTo(&flu.PlainText{Value: strings.Repeat("x", 100)}, flu.Encrypted{Out: buf, Key: key, ChunkSize: 32})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	data := buf.Bytes()
+	tampered := append(flu.Bytes{}, data...)
+	tampered[len(tampered)-1] ^= 1
+	err = flu.DecodeFrom(flu.Encrypted{In: tampered, Key: key}, new(flu.PlainText))
+	assert.Equal(t, flu.AuthenticationError{Chunk: 3}, err)
+
+	// the chunk size in the header
+	tampered = append(flu.Bytes{}, data...)
+	tampered[9] ^= 1
+	err = flu.DecodeFrom(flu.Encrypted{In: tampered, Key: key}, new(flu.PlainText))
+	assert.Equal(t, flu.AuthenticationError{Chunk: 0}, err)
+
+	// truncated at the chunk boundary
+	header := len(data) - 4*16 - 100
+	truncated := data[:header+2*(32+16)]
+	err = flu.DecodeFrom(flu.Encrypted{In: truncated, Key: key}, new(flu.PlainText))
+	assert.Equal(t, flu.AuthenticationError{Chunk: 1}, err)
+}
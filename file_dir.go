@@ -0,0 +1,147 @@
+package flu
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Stat returns the File info.
+func (f File) Stat() (os.FileInfo, error) {
+	return f.On(OS).Stat()
+}
+
+// Size returns the File size in bytes.
+func (f File) Size() (int64, error) {
+	return f.On(OS).Size()
+}
+
+// ModTime returns the File modification time.
+func (f File) ModTime() (time.Time, error) {
+	return f.On(OS).ModTime()
+}
+
+// IsDir checks if the File is a directory.
+func (f File) IsDir() (bool, error) {
+	return f.On(OS).IsDir()
+}
+
+// List returns the directory children sorted by name.
+func (f File) List() ([]File, error) {
+	children, err := f.On(OS).List()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]File, len(children))
+	for i, child := range children {
+		files[i] = File(child.Path)
+	}
+
+	return files, nil
+}
+
+// Glob returns the files matching the pattern relative to this directory
+// (see filepath.Match for the pattern syntax).
+func (f File) Glob(pattern string) ([]File, error) {
+	matches, err := filepath.Glob(filepath.Join(f.Path(), pattern))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]File, len(matches))
+	for i, match := range matches {
+		files[i] = File(match)
+	}
+
+	return files, nil
+}
+
+// WalkOptions configure File.Walk and FSFile.Walk.
+// Patterns use filepath.Match syntax and are matched against
+// both the slash-separated path relative to the walk root and the base name.
+type WalkOptions struct {
+	// Include patterns select the files to be visited.
+	// All files are visited if empty.
+	Include []string
+	// Exclude patterns exclude files and whole directories.
+	Exclude []string
+	// Dirs enables visiting directories (Include does not apply to them).
+	Dirs bool
+}
+
+func (o WalkOptions) matches(patterns []string, rel string) bool {
+	base := filepath.Base(rel)
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+
+		if ok, _ := filepath.Match(pattern, base); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Walk walks the file tree rooted at this File in lexical order.
+// Returning filepath.SkipDir from the callback skips the directory.
+func (f File) Walk(options WalkOptions, fun func(file File, info os.FileInfo) error) error {
+	return f.On(OS).Walk(options, func(file FSFile, info os.FileInfo) error {
+		return fun(File(file.Path), info)
+	})
+}
+
+// CopyTo copies the file or directory tree to the target File (see FSFile.CopyTo).
+func (f File) CopyTo(target File, overwrite bool) error {
+	return f.On(OS).CopyTo(target.On(OS), overwrite)
+}
+
+// MoveTo moves the file or directory tree to the target File (see FSFile.MoveTo).
+func (f File) MoveTo(target File, overwrite bool) error {
+	return f.On(OS).MoveTo(target.On(OS), overwrite)
+}
+
+// TempFile creates a new empty temporary file
+// (see os.CreateTemp for the pattern syntax).
+func TempFile(pattern string) (File, error) {
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+
+	return File(file.Name()), file.Close()
+}
+
+// TempDir creates a new temporary directory
+// (see os.MkdirTemp for the pattern syntax).
+func TempDir(pattern string) (File, error) {
+	dir, err := os.MkdirTemp("", pattern)
+	return File(dir), err
+}
+
+// WithTempFile creates a temporary file, executes the function
+// and removes the file afterwards.
+func WithTempFile(pattern string, fun func(file File) error) error {
+	file, err := TempFile(pattern)
+	if err != nil {
+		return err
+	}
+
+	defer file.Remove()
+	return fun(file)
+}
+
+// WithTempDir creates a temporary directory, executes the function
+// and removes the directory with all its contents afterwards.
+func WithTempDir(pattern string, fun func(dir File) error) error {
+	dir, err := TempDir(pattern)
+	if err != nil {
+		return err
+	}
+
+	defer dir.Remove()
+	return fun(dir)
+}
@@ -0,0 +1,122 @@
+package flu_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/jfk9w-go/flu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFile_Walk(t *testing.T) {
+	err := flu.WithTempDir("flu", func(dir flu.File) error {
+		for _, name := range []string{"a.json", "b.yml", "sub/c.json", "skip/d.json"} {
+			if err := flu.EncodeTo(&flu.PlainText{Value: name}, dir.Join(name)); err != nil {
+				return err
+			}
+		}
+
+		var visited []string
+		err := dir.Walk(flu.WalkOptions{Include: []string{"*.json"}, Exclude: []string{"skip"}},
+			func(file flu.File, info os.FileInfo) error {
+				visited = append(visited, file.Path()[len(dir.Path())+1:])
+				return nil
+			})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a.json", "sub/c.json"}, visited)
+
+		children, err := dir.List()
+		assert.Nil(t, err)
+		assert.Equal(t, []flu.File{dir.Join("a.json"), dir.Join("b.yml"), dir.Join("skip"), dir.Join("sub")}, children)
+
+		isDir, err := dir.Join("sub").IsDir()
+		assert.Nil(t, err)
+		assert.True(t, isDir)
+
+		size, err := dir.Join("b.yml").Size()
+		assert.Nil(t, err)
+		assert.Equal(t, int64(5), size)
+		return nil
+	})
+
+	assert.Nil(t, err)
+}
+
+func TestFile_CopyTo_MoveTo(t *testing.T) {
+	err := flu.WithTempDir("flu", func(dir flu.File) error {
+		source := dir.Join("source")
+		assert.Nil(t, flu.EncodeTo(&flu.PlainText{Value: "a"}, source.Join("a.txt")))
+		assert.Nil(t, flu.EncodeTo(&flu.PlainText{Value: "b"}, source.Join("sub/b.txt")))
+
+		copied := dir.Join("copied")
+		assert.Nil(t, source.CopyTo(copied, false))
+		err := source.CopyTo(copied, false)
+		assert.True(t, os.IsExist(err))
+		assert.Nil(t, source.CopyTo(copied, true))
+
+		moved := dir.Join("moved")
+		assert.Nil(t, copied.MoveTo(moved, false))
+		assert.True(t, os.IsExist(source.MoveTo(moved, false)))
+
+		text := new(flu.PlainText)
+		assert.Nil(t, flu.DecodeFrom(moved.Join("sub/b.txt"), text))
+		assert.Equal(t, "b", text.Value)
+
+		exists, err := copied.Exists()
+		assert.Nil(t, err)
+		assert.False(t, exists)
+		return nil
+	})
+
+	assert.Nil(t, err)
+}
+
+func TestFile_CopyTo_MoveTo_SamePath(t *testing.T) {
+	err := flu.WithTempDir("flu", func(dir flu.File) error {
+		file := dir.Join("a.txt")
+		assert.Nil(t, flu.EncodeTo(&flu.PlainText{Value: "a"}, file))
+		assert.Nil(t, flu.EncodeTo(&flu.PlainText{Value: "b"}, dir.Join("sub/b.txt")))
+
+		assert.Error(t, file.CopyTo(file, true))
+		assert.Error(t, file.CopyTo(flu.File(dir.Path()+"/./a.txt"), true))
+		assert.Nil(t, file.MoveTo(file, true))
+		assert.Nil(t, file.MoveTo(file, false))
+
+		text := new(flu.PlainText)
+		assert.Nil(t, flu.DecodeFrom(file, text))
+		assert.Equal(t, "a", text.Value)
+
+		assert.Error(t, dir.CopyTo(dir.Join("sub/copy"), true))
+		assert.Error(t, dir.Join("sub").MoveTo(dir.Join("sub/nested"), true))
+		exists, err := dir.Join("sub/b.txt").Exists()
+		assert.Nil(t, err)
+		assert.True(t, exists)
+		return nil
+	})
+
+	assert.Nil(t, err)
+}
+
+func TestFile_MoveTo_Overwrite(t *testing.T) {
+	err := flu.WithTempDir("flu", func(dir flu.File) error {
+		assert.Nil(t, flu.EncodeTo(&flu.PlainText{Value: "a"}, dir.Join("a.txt")))
+		assert.Nil(t, flu.EncodeTo(&flu.PlainText{Value: "b"}, dir.Join("b.txt")))
+		assert.Nil(t, dir.Join("a.txt").MoveTo(dir.Join("b.txt"), true))
+
+		text := new(flu.PlainText)
+		assert.Nil(t, flu.DecodeFrom(dir.Join("b.txt"), text))
+		assert.Equal(t, "a", text.Value)
+
+		assert.Nil(t, flu.EncodeTo(&flu.PlainText{Value: "c"}, dir.Join("source/c.txt")))
+		assert.Nil(t, flu.EncodeTo(&flu.PlainText{Value: "d"}, dir.Join("target/d.txt")))
+		assert.Nil(t, dir.Join("source").MoveTo(dir.Join("target"), true))
+
+		children, err := dir.Join("target").List()
+		assert.Nil(t, err)
+		assert.Equal(t, []flu.File{dir.Join("target/c.txt")}, children)
+		return nil
+	})
+
+	assert.Nil(t, err)
+}
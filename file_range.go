@@ -0,0 +1,55 @@
+package flu
+
+import (
+	"io"
+)
+
+// FileRange is an Input reading the byte range [Offset, Offset+Length) of the File.
+type FileRange struct {
+	// File is the source File.
+	File File
+	// Offset is the range start.
+	Offset int64
+	// Length is the range length. Zero or negative value means "until the end".
+	Length int64
+}
+
+// Range returns the byte range [offset, offset+length) of the File.
+// Zero or negative length means "until the end".
+func (f File) Range(offset, length int64) FileRange {
+	return FileRange{File: f, Offset: offset, Length: length}
+}
+
+func (r FileRange) Reader() (io.Reader, error) {
+	file, err := r.File.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := file.Seek(r.Offset, io.SeekStart); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	if r.Length <= 0 {
+		return file, nil
+	}
+
+	return chainReader{io.LimitReader(file, r.Length), []interface{}{file}}, nil
+}
+
+// Size returns the total size of the File.
+func (r FileRange) Size() (int64, error) {
+	file, err := r.File.Open()
+	if err != nil {
+		return 0, err
+	}
+
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	return info.Size(), nil
+}
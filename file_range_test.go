@@ -0,0 +1,36 @@
+package flu_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/jfk9w-go/flu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileRange(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flu")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	file := flu.FilePath(dir, "data.txt")
+	err = flu.EncodeTo(&flu.PlainText{Value: "0123456789"}, file)
+	assert.Nil(t, err)
+
+	text := new(flu.PlainText)
+	err = flu.DecodeFrom(file.Range(3, 4), text)
+	assert.Nil(t, err)
+	assert.Equal(t, "3456", text.Value)
+
+	err = flu.DecodeFrom(file.Range(8, 0), text)
+	assert.Nil(t, err)
+	assert.Equal(t, "89", text.Value)
+
+	size, err := file.Range(0, 0).Size()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), size)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"log"
+	"sync"
 	"time"
 )
 
@@ -36,78 +37,97 @@ type DecoderFrom interface {
 // EncodeTo encodes the provided EncoderTo to Output.
 // It closes the io.Writer instance if necessary.
 func EncodeTo(encoder EncoderTo, out Output) error {
-	w, err := out.Writer()
-	if err != nil {
-		return err
-	}
-	if err := encoder.EncodeTo(w); err != nil {
-		return err
-	}
-	return Close(w)
+	return EncodeToContext(context.Background(), encoder, out)
 }
 
 // DecodeFrom decodes the provided DecoderFrom from Input.
 // It closes the io.Reader instance if necessary.
 func DecodeFrom(in Input, decoder DecoderFrom) error {
-	r, err := in.Reader()
-	if err != nil {
-		return err
-	}
-	if err := decoder.DecodeFrom(r); err != nil {
-		return err
-	}
-	return Close(r)
+	return DecodeFromContext(context.Background(), in, decoder)
 }
 
 // PipeInput pipes the encoded value from EncoderTo as Input
 // in the background.
+// Encoding starts on every Reader call and stops
+// when the io.Reader is closed or the context is done.
 func PipeInput(encoder EncoderTo) Input {
+	return pipeInput{encoder}
+}
+
+type pipeInput struct {
+	encoder EncoderTo
+}
+
+func (p pipeInput) Reader() (io.Reader, error) {
+	return p.ReaderContext(context.Background())
+}
+
+func (p pipeInput) ReaderContext(ctx context.Context) (io.Reader, error) {
 	r, w := io.Pipe()
 	go func() {
-		err := encoder.EncodeTo(w)
+		err := p.encoder.EncodeTo(w)
 		if err := w.CloseWithError(err); err != nil {
 			log.Printf("PipeInput close error: %s", err)
 		}
 	}()
 
-	return IO{R: r}
+	return WithContext(ctx, r).(io.Reader), nil
 }
 
 // PipeOutput provides an Output which feeds into DecoderFrom
 // in the background.
+// Decoding starts on every Writer call. Closing the io.Writer
+// waits for the decoding to complete and returns its error.
 func PipeOutput(decoder DecoderFrom) Output {
+	return pipeOutput{decoder}
+}
+
+type pipeOutput struct {
+	decoder DecoderFrom
+}
+
+func (p pipeOutput) Writer() (io.Writer, error) {
+	return p.WriterContext(context.Background())
+}
+
+func (p pipeOutput) WriterContext(ctx context.Context) (io.Writer, error) {
 	r, w := io.Pipe()
+	done := make(chan error, 1)
 	go func() {
-		err := decoder.DecodeFrom(r)
+		err := p.decoder.DecodeFrom(r)
 		if err := r.CloseWithError(err); err != nil {
 			log.Printf("PipeOutput close error: %s", err)
 		}
+
+		done <- err
 	}()
 
-	return IO{W: w}
+	return WithContext(ctx, &pipeWriter{PipeWriter: w, done: done}).(io.Writer), nil
+}
+
+type pipeWriter struct {
+	*io.PipeWriter
+	done <-chan error
+	once sync.Once
+	err  error
+}
+
+func (w *pipeWriter) Close() error {
+	return w.CloseWithError(nil)
+}
+
+func (w *pipeWriter) CloseWithError(err error) error {
+	if err := w.PipeWriter.CloseWithError(err); err != nil {
+		return err
+	}
+
+	w.once.Do(func() { w.err = <-w.done })
+	return w.err
 }
 
 // Copy copies the Input to the Output.
 func Copy(in Input, out Output) (written int64, err error) {
-	r, err := in.Reader()
-	if err != nil {
-		return
-	}
-	w, err := out.Writer()
-	if err != nil {
-		return
-	}
-	written, err = io.Copy(w, r)
-	if err != nil {
-		return
-	}
-	if err = Close(w); err != nil {
-		return
-	}
-	if err = Close(r); err != nil {
-		return
-	}
-	return
+	return CopyContext(context.Background(), in, out)
 }
 
 // Sleep sleeps for the specified timeout interruptibly.
@@ -0,0 +1,560 @@
+package flu
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	errSameFile       = errors.New("source and target are the same file")
+	errTargetInSource = errors.New("target is inside the source directory")
+)
+
+// FS is a filesystem abstraction.
+type FS interface {
+	// Open opens the named file for reading.
+	Open(name string) (io.ReadCloser, error)
+	// Create creates or truncates the named file for writing.
+	// The parent directory must exist.
+	Create(name string) (io.WriteCloser, error)
+	// Stat returns the named file info.
+	Stat(name string) (fs.FileInfo, error)
+	// Remove removes the named file or directory (recursively).
+	// It returns nil if the file does not exist.
+	Remove(name string) error
+	// MkdirAll creates the directory along with any necessary parents.
+	MkdirAll(name string) error
+	// ReadDir returns the directory entries sorted by filename.
+	ReadDir(name string) ([]fs.DirEntry, error)
+	// Rename renames (moves) oldname to newname.
+	Rename(oldname, newname string) error
+}
+
+// OS is the FS implementation backed by the os package.
+var OS FS = osFS{}
+
+type osFS struct{}
+
+func (osFS) Open(name string) (io.ReadCloser, error) {
+	return os.Open(name)
+}
+
+func (osFS) Create(name string) (io.WriteCloser, error) {
+	return os.Create(name)
+}
+
+func (osFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (osFS) Remove(name string) error {
+	return os.RemoveAll(name)
+}
+
+func (osFS) MkdirAll(name string) error {
+	return os.MkdirAll(name, os.ModePerm)
+}
+
+func (osFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+func (osFS) Rename(oldname, newname string) error {
+	return os.Rename(oldname, newname)
+}
+
+func (osFS) createPerm(name string, perm fs.FileMode) (io.WriteCloser, error) {
+	return os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+}
+
+func (osFS) mkdirAllPerm(name string, perm fs.FileMode) error {
+	return os.MkdirAll(name, perm)
+}
+
+func (osFS) abs(name string) string {
+	if abs, err := filepath.Abs(name); err == nil {
+		return abs
+	}
+
+	return filepath.Clean(name)
+}
+
+// permFS is implemented by FS which support file permissions.
+type permFS interface {
+	createPerm(name string, perm fs.FileMode) (io.WriteCloser, error)
+	mkdirAllPerm(name string, perm fs.FileMode) error
+}
+
+// ReadOnlyFS adapts fs.FS (like embed.FS) to FS.
+// All modifying operations fail with fs.ErrPermission.
+func ReadOnlyFS(fsys fs.FS) FS {
+	return readOnlyFS{fsys}
+}
+
+type readOnlyFS struct {
+	fsys fs.FS
+}
+
+func (r readOnlyFS) Open(name string) (io.ReadCloser, error) {
+	return r.fsys.Open(slashPath(name))
+}
+
+func (r readOnlyFS) Create(name string) (io.WriteCloser, error) {
+	return nil, &fs.PathError{Op: "create", Path: name, Err: fs.ErrPermission}
+}
+
+func (r readOnlyFS) Stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(r.fsys, slashPath(name))
+}
+
+func (r readOnlyFS) Remove(name string) error {
+	return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrPermission}
+}
+
+func (r readOnlyFS) MkdirAll(name string) error {
+	return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrPermission}
+}
+
+func (r readOnlyFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(r.fsys, slashPath(name))
+}
+
+func (r readOnlyFS) Rename(oldname, newname string) error {
+	return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: fs.ErrPermission}
+}
+
+// slashPath converts the path to the unrooted slash-separated form used by fs.FS.
+func slashPath(name string) string {
+	name = path.Clean("/" + filepath.ToSlash(name))
+	if name == "/" {
+		return "."
+	}
+
+	return name[1:]
+}
+
+// FSFile is a path representing a file (or directory) in FS.
+type FSFile struct {
+	// FS is the filesystem.
+	FS FS
+	// Path is the file path in FS.
+	Path string
+}
+
+// On creates an FSFile instance pointing to this File in FS.
+func (f File) On(fsys FS) FSFile {
+	return FSFile{FS: fsys, Path: f.Path()}
+}
+
+// Join creates a new FSFile instance pointing
+// to the child element of this instance.
+func (f FSFile) Join(child string) FSFile {
+	return FSFile{FS: f.FS, Path: filepath.Join(f.Path, child)}
+}
+
+// Exists checks for the existence of the FSFile entry.
+func (f FSFile) Exists() (bool, error) {
+	_, err := f.FS.Stat(f.Path)
+	if err != nil && os.IsNotExist(err) {
+		return false, nil
+	} else if err == nil {
+		return true, nil
+	} else {
+		return false, err
+	}
+}
+
+// Stat returns the FSFile info.
+func (f FSFile) Stat() (fs.FileInfo, error) {
+	return f.FS.Stat(f.Path)
+}
+
+// Size returns the FSFile size in bytes.
+func (f FSFile) Size() (int64, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	return info.Size(), nil
+}
+
+// ModTime returns the FSFile modification time.
+func (f FSFile) ModTime() (time.Time, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return info.ModTime(), nil
+}
+
+// IsDir checks if the FSFile is a directory.
+func (f FSFile) IsDir() (bool, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	return info.IsDir(), nil
+}
+
+// Open opens the FSFile for reading.
+func (f FSFile) Open() (io.ReadCloser, error) {
+	return f.FS.Open(f.Path)
+}
+
+// Create opens the FSFile for writing.
+// It creates the file and all intermediate directories if necessary.
+func (f FSFile) Create() (io.WriteCloser, error) {
+	if err := f.FS.MkdirAll(filepath.Dir(f.Path)); err != nil {
+		return nil, err
+	}
+
+	return f.FS.Create(f.Path)
+}
+
+func (f FSFile) Reader() (io.Reader, error) {
+	return f.Open()
+}
+
+func (f FSFile) Writer() (io.Writer, error) {
+	return f.Create()
+}
+
+// List returns the directory children.
+func (f FSFile) List() ([]FSFile, error) {
+	entries, err := f.FS.ReadDir(f.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make([]FSFile, len(entries))
+	for i, entry := range entries {
+		children[i] = f.Join(entry.Name())
+	}
+
+	return children, nil
+}
+
+// Rename renames (moves) the FSFile to the target path.
+func (f FSFile) Rename(target string) (FSFile, error) {
+	if err := f.FS.MkdirAll(filepath.Dir(target)); err != nil {
+		return f, err
+	}
+
+	if err := f.FS.Rename(f.Path, target); err != nil {
+		return f, err
+	}
+
+	return FSFile{FS: f.FS, Path: target}, nil
+}
+
+// Remove removes the file or directory represented by this FSFile.
+func (f FSFile) Remove() error {
+	return f.FS.Remove(f.Path)
+}
+
+// Walk walks the file tree rooted at this FSFile in lexical order.
+// Returning filepath.SkipDir from the callback skips the directory.
+func (f FSFile) Walk(options WalkOptions, fun func(file FSFile, info fs.FileInfo) error) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	err = f.walk(f.Path, info, options, fun)
+	if err == filepath.SkipDir {
+		return nil
+	}
+
+	return err
+}
+
+func (f FSFile) walk(root string, info fs.FileInfo, options WalkOptions, fun func(file FSFile, info fs.FileInfo) error) error {
+	rel, err := filepath.Rel(root, f.Path)
+	if err != nil {
+		return err
+	}
+
+	if rel != "." && options.matches(options.Exclude, rel) {
+		if info.IsDir() {
+			return filepath.SkipDir
+		}
+
+		return nil
+	}
+
+	if !info.IsDir() {
+		if len(options.Include) > 0 && !options.matches(options.Include, rel) {
+			return nil
+		}
+
+		return fun(f, info)
+	}
+
+	if options.Dirs {
+		if err := fun(f, info); err != nil {
+			return err
+		}
+	}
+
+	entries, err := f.FS.ReadDir(f.Path)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		// entry info does not follow symlinks
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		// filepath.SkipDir returned for a file skips the rest of its directory
+		if err := f.Join(entry.Name()).walk(root, info, options, fun); err != nil &&
+			(!info.IsDir() || err != filepath.SkipDir) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sameFS compares FS instances.
+// FS with uncomparable dynamic types (like ReadOnlyFS(fstest.MapFS))
+// are considered different.
+func sameFS(a, b FS) (same bool) {
+	defer func() {
+		if recover() != nil {
+			same = false
+		}
+	}()
+
+	return a == b
+}
+
+// abs returns the absolute path used for comparing FSFiles.
+func (f FSFile) abs() string {
+	if fsys, ok := f.FS.(osFS); ok {
+		return fsys.abs(f.Path)
+	}
+
+	return filepath.Join(string(filepath.Separator), f.Path)
+}
+
+// sameFile checks if the target points to the same file as this FSFile.
+func (f FSFile) sameFile(target FSFile, info fs.FileInfo) bool {
+	if f.abs() == target.abs() {
+		return true
+	}
+
+	targetInfo, err := target.Stat()
+	return err == nil && os.SameFile(info, targetInfo)
+}
+
+// contains checks if the target is located inside this FSFile directory.
+func (f FSFile) contains(target FSFile) bool {
+	rel, err := filepath.Rel(f.abs(), target.abs())
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// checkTarget checks if the FSFile can be copied or moved to the target.
+func (f FSFile) checkTarget(op string, target FSFile, info fs.FileInfo) error {
+	if f.sameFile(target, info) {
+		return &os.LinkError{Op: op, Old: f.Path, New: target.Path, Err: errSameFile}
+	}
+
+	if info.IsDir() && f.contains(target) {
+		return &os.LinkError{Op: op, Old: f.Path, New: target.Path, Err: errTargetInSource}
+	}
+
+	return nil
+}
+
+// CopyTo copies the file or directory tree to the target FSFile
+// (which may reside in another FS).
+// If overwrite is false and the target file exists, the copying fails with os.ErrExist.
+// Copying a file onto itself or a directory into its own subtree fails.
+// File permissions are preserved if both FS support them.
+func (f FSFile) CopyTo(target FSFile, overwrite bool) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if sameFS(f.FS, target.FS) {
+		if err := f.checkTarget("copy", target, info); err != nil {
+			return err
+		}
+	}
+
+	if !info.IsDir() {
+		return copyFile(f, target, info.Mode(), overwrite)
+	}
+
+	return f.Walk(WalkOptions{Dirs: true}, func(file FSFile, info fs.FileInfo) error {
+		rel, err := filepath.Rel(f.Path, file.Path)
+		if err != nil {
+			return err
+		}
+
+		dst := target.Join(rel)
+		if info.IsDir() {
+			if fsys, ok := dst.FS.(permFS); ok {
+				return fsys.mkdirAllPerm(dst.Path, info.Mode().Perm())
+			}
+
+			return dst.FS.MkdirAll(dst.Path)
+		}
+
+		return copyFile(file, dst, info.Mode(), overwrite)
+	})
+}
+
+func copyFile(source, target FSFile, mode fs.FileMode, overwrite bool) error {
+	if !overwrite {
+		if exists, err := target.Exists(); err != nil {
+			return err
+		} else if exists {
+			return &os.PathError{Op: "copy", Path: target.Path, Err: os.ErrExist}
+		}
+	}
+
+	r, err := source.Open()
+	if err != nil {
+		return err
+	}
+
+	defer r.Close()
+	if err := target.FS.MkdirAll(filepath.Dir(target.Path)); err != nil {
+		return err
+	}
+
+	var w io.WriteCloser
+	if fsys, ok := target.FS.(permFS); ok {
+		w, err = fsys.createPerm(target.Path, mode.Perm())
+	} else {
+		w, err = target.FS.Create(target.Path)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(w, r); err != nil {
+		_ = w.Close()
+		return err
+	}
+
+	return w.Close()
+}
+
+// MoveTo moves the file or directory tree to the target FSFile
+// (which may reside in another FS).
+// If overwrite is false and the target exists, the moving fails with os.ErrExist.
+// Otherwise, a target file is replaced atomically by FS.Rename.
+// A target which can not be replaced by renaming (like a non-empty directory)
+// is moved aside first and restored if the moving fails.
+// Moving a file onto itself is a no-op, moving a directory
+// into its own subtree fails.
+// Moving between FS or devices is performed by copying and removing the source.
+func (f FSFile) MoveTo(target FSFile, overwrite bool) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	local := sameFS(f.FS, target.FS)
+	if local {
+		if err := f.checkTarget("move", target, info); err != nil {
+			if errors.Is(err, errSameFile) {
+				return nil
+			}
+
+			return err
+		}
+	}
+
+	exists, err := target.Exists()
+	if err != nil {
+		return err
+	} else if exists && !overwrite {
+		return &os.PathError{Op: "move", Path: target.Path, Err: os.ErrExist}
+	}
+
+	if local {
+		if err := f.FS.MkdirAll(filepath.Dir(target.Path)); err != nil {
+			return err
+		}
+
+		err := f.FS.Rename(f.Path, target.Path)
+		switch {
+		case err == nil:
+			return nil
+		case errors.Is(err, syscall.EXDEV):
+		case exists && isRenameConflict(err):
+			// renaming does not replace non-empty directories
+			// or files with directories (and vice versa)
+			return target.replace(func() error { return f.FS.Rename(f.Path, target.Path) })
+		default:
+			return err
+		}
+	}
+
+	if exists {
+		return target.replace(func() error { return f.moveByCopy(target) })
+	}
+
+	return f.moveByCopy(target)
+}
+
+func isRenameConflict(err error) bool {
+	return errors.Is(err, fs.ErrExist) || errors.Is(err, syscall.EISDIR) || errors.Is(err, syscall.ENOTDIR)
+}
+
+// replace moves this FSFile aside, calls the function which creates a new one
+// and removes the old one. The old FSFile is restored if the function fails.
+func (f FSFile) replace(create func() error) error {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+
+	aside := FSFile{FS: f.FS, Path: filepath.Join(filepath.Dir(f.Path),
+		"."+filepath.Base(f.Path)+".old-"+hex.EncodeToString(suffix))}
+	if err := f.FS.Rename(f.Path, aside.Path); err != nil {
+		return err
+	}
+
+	if err := create(); err != nil {
+		_ = f.Remove()
+		if restoreErr := f.FS.Rename(aside.Path, f.Path); restoreErr != nil {
+			return fmt.Errorf("%w (restore %s: %s)", err, aside.Path, restoreErr)
+		}
+
+		return err
+	}
+
+	return aside.Remove()
+}
+
+// moveByCopy copies the FSFile to the non-existing target and removes the source.
+func (f FSFile) moveByCopy(target FSFile) error {
+	if err := f.CopyTo(target, false); err != nil {
+		_ = target.Remove()
+		return err
+	}
+
+	return f.Remove()
+}
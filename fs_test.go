@@ -0,0 +1,142 @@
+package flu_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jfk9w-go/flu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemFS(t *testing.T) {
+	root := flu.File("state").On(flu.NewMemFS())
+	file := root.Join("nested").Join("value.json")
+	err := flu.EncodeTo(flu.JSON{Value: map[string]int{"a": 1}}, file)
+	assert.Nil(t, err)
+
+	exists, err := file.Exists()
+	assert.Nil(t, err)
+	assert.True(t, exists)
+
+	value := make(map[string]int)
+	err = flu.DecodeFrom(file, flu.JSON{Value: &value})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"a": 1}, value)
+
+	moved, err := root.Join("nested").Rename("state/moved")
+	assert.Nil(t, err)
+
+	children, err := root.List()
+	assert.Nil(t, err)
+	assert.Equal(t, []flu.FSFile{moved}, children)
+
+	info, err := moved.Join("value.json").Stat()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(8), info.Size())
+
+	assert.Nil(t, root.Remove())
+	exists, err = moved.Join("value.json").Exists()
+	assert.Nil(t, err)
+	assert.False(t, exists)
+}
+
+func TestReadOnlyFS(t *testing.T) {
+	fsys := flu.ReadOnlyFS(fstest.MapFS{
+		"config/app.yml": &fstest.MapFile{Data: []byte("name: test\n")},
+	})
+
+	value := make(map[string]string)
+	err := flu.DecodeFrom(flu.File("/config/app.yml").On(fsys), flu.YAML{Value: &value})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"name": "test"}, value)
+
+	err = flu.EncodeTo(flu.YAML{Value: value}, flu.File("config/other.yml").On(fsys))
+	assert.True(t, errors.Is(err, fs.ErrPermission))
+}
+
+func TestFSFile_Walk_CopyTo_MoveTo(t *testing.T) {
+	root := flu.File("root").On(flu.NewMemFS())
+	for _, name := range []string{"a.json", "sub/b.json", "sub/c.yml"} {
+		assert.Nil(t, flu.EncodeTo(&flu.PlainText{Value: name}, root.Join("source").Join(name)))
+	}
+
+	var visited []string
+	err := root.Join("source").Walk(flu.WalkOptions{Include: []string{"*.json"}},
+		func(file flu.FSFile, info fs.FileInfo) error {
+			visited = append(visited, file.Path)
+			return nil
+		})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"root/source/a.json", "root/source/sub/b.json"}, visited)
+
+	assert.Nil(t, root.Join("source").CopyTo(root.Join("copied"), false))
+	assert.Error(t, root.Join("source").CopyTo(root.Join("source/sub/copied"), true))
+	assert.Nil(t, root.Join("copied").MoveTo(root.Join("moved"), false))
+
+	text := new(flu.PlainText)
+	assert.Nil(t, flu.DecodeFrom(root.Join("moved/sub/c.yml"), text))
+	assert.Equal(t, "sub/c.yml", text.Value)
+
+	other := flu.File("other").On(flu.NewMemFS())
+	assert.Nil(t, root.Join("moved").MoveTo(other, false))
+	size, err := other.Join("a.json").Size()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(6), size)
+
+	exists, err := root.Join("moved").Exists()
+	assert.Nil(t, err)
+	assert.False(t, exists)
+}
+
+// renameFailingFS fails renaming of the source path with a conflict error.
+type renameFailingFS struct {
+	*flu.MemFS
+	source string
+}
+
+func (fsys renameFailingFS) Rename(oldname, newname string) error {
+	if oldname == fsys.source {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: syscall.ENOTEMPTY}
+	}
+
+	return fsys.MemFS.Rename(oldname, newname)
+}
+
+func TestFSFile_MoveTo_RestoresTarget(t *testing.T) {
+	fsys := renameFailingFS{MemFS: flu.NewMemFS(), source: "source"}
+	source, target := flu.File("source").On(fsys), flu.File("target").On(fsys)
+	assert.Nil(t, flu.EncodeTo(&flu.PlainText{Value: "a"}, source.Join("a.txt")))
+	assert.Nil(t, flu.EncodeTo(&flu.PlainText{Value: "b"}, target.Join("b.txt")))
+
+	assert.Error(t, source.MoveTo(target, true))
+	children, err := target.List()
+	assert.Nil(t, err)
+	assert.Equal(t, []flu.FSFile{target.Join("b.txt")}, children)
+
+	children, err = flu.File(".").On(fsys).List()
+	assert.Nil(t, err)
+	assert.Equal(t, []flu.FSFile{flu.File("source").On(fsys), target}, children)
+}
+
+func TestFSFile_MoveTo_OtherFS(t *testing.T) {
+	source := flu.File("source").On(flu.NewMemFS())
+	target := flu.File("target").On(flu.NewMemFS())
+	assert.Nil(t, flu.EncodeTo(&flu.PlainText{Value: "a"}, source.Join("a.txt")))
+	assert.Nil(t, flu.EncodeTo(&flu.PlainText{Value: "b"}, target.Join("b.txt")))
+
+	err := source.MoveTo(target, false)
+	assert.True(t, os.IsExist(err))
+	exists, err := source.Exists()
+	assert.Nil(t, err)
+	assert.True(t, exists)
+
+	assert.Nil(t, source.MoveTo(target, true))
+	children, err := target.List()
+	assert.Nil(t, err)
+	assert.Equal(t, []flu.FSFile{target.Join("a.txt")}, children)
+}
@@ -0,0 +1,151 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ContentRangeError is returned when the server responds
+// with an unexpected Content-Range.
+type ContentRangeError string
+
+func (e ContentRangeError) Error() string {
+	return fmt.Sprintf("invalid content range: %s", string(e))
+}
+
+// ByteRange is a flu.Input reading the byte range [Offset, Offset+Length)
+// of the remote resource using Range requests.
+// Note that if the Client has accepted statuses set (see Client.AcceptStatus),
+// they must include http.StatusPartialContent.
+type ByteRange struct {
+	// Client is the Client used for requests.
+	Client *Client
+	// URL is the resource URL.
+	URL string
+	// Offset is the range start.
+	Offset int64
+	// Length is the range length. Zero or negative value means "until the end".
+	Length int64
+
+	total int64
+}
+
+// ByteRange creates a ByteRange for the resource.
+func (c *Client) ByteRange(rawurl string, offset, length int64) *ByteRange {
+	return &ByteRange{Client: c, URL: rawurl, Offset: offset, Length: length, total: -1}
+}
+
+func (r *ByteRange) Reader() (io.Reader, error) {
+	return r.ReaderContext(context.Background())
+}
+
+func (r *ByteRange) ReaderContext(ctx context.Context) (io.Reader, error) {
+	r.total = -1
+	spec := fmt.Sprintf("bytes=%d-", r.Offset)
+	if r.Length > 0 {
+		spec += strconv.FormatInt(r.Offset+r.Length-1, 10)
+	}
+
+	resp := r.Client.GET(r.URL).
+		SetHeader("Range", spec).
+		Context(ctx).
+		Execute()
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		start, end, total, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil {
+			_ = resp.Body.Close()
+			return nil, err
+		}
+
+		if start != r.Offset || r.Length > 0 && end > r.Offset+r.Length-1 {
+			_ = resp.Body.Close()
+			return nil, ContentRangeError(resp.Header.Get("Content-Range"))
+		}
+
+		r.total = total
+		return resp.Body, nil
+	case http.StatusOK:
+		if r.Offset != 0 {
+			_ = resp.Body.Close()
+			return nil, ContentRangeError("range requests are not supported by server")
+		}
+
+		r.total = resp.ContentLength
+		if r.Length > 0 {
+			return struct {
+				io.Reader
+				io.Closer
+			}{io.LimitReader(resp.Body, r.Length), resp.Body}, nil
+		}
+
+		return resp.Body, nil
+	default:
+		err := NewStatusCodeError(resp.Response)
+		_ = resp.Body.Close()
+		return nil, err
+	}
+}
+
+// Total returns the total resource size reported by the server
+// during the last Reader call or -1 if it is unknown.
+func (r *ByteRange) Total() int64 {
+	return r.total
+}
+
+// Size requests the total resource size with a HEAD request.
+func (r *ByteRange) Size(ctx context.Context) (int64, error) {
+	resp := r.Client.HEAD(r.URL).Context(ctx).Execute()
+	if resp.Error != nil {
+		return 0, resp.Error
+	}
+
+	_ = resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return 0, StatusCodeError{StatusCode: resp.StatusCode}
+	}
+
+	return resp.ContentLength, nil
+}
+
+// parseContentRange parses "bytes start-end/total" header value.
+// total is -1 if it is unknown ("*").
+func parseContentRange(value string) (start, end, total int64, err error) {
+	err = ContentRangeError(value)
+	spec := strings.TrimPrefix(value, "bytes ")
+	if spec == value {
+		return
+	}
+
+	slash := strings.IndexByte(spec, '/')
+	dash := strings.IndexByte(spec, '-')
+	if slash < 0 || dash < 0 || dash > slash {
+		return
+	}
+
+	var parseErr error
+	if start, parseErr = strconv.ParseInt(spec[:dash], 10, 64); parseErr != nil {
+		return
+	}
+
+	if end, parseErr = strconv.ParseInt(spec[dash+1:slash], 10, 64); parseErr != nil || end < start {
+		return
+	}
+
+	total = -1
+	if spec[slash+1:] != "*" {
+		if total, parseErr = strconv.ParseInt(spec[slash+1:], 10, 64); parseErr != nil {
+			return
+		}
+	}
+
+	return start, end, total, nil
+}
@@ -0,0 +1,51 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jfk9w-go/flu"
+	fluhttp "github.com/jfk9w-go/flu/http"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_ByteRange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		http.ServeContent(writer, req, "data.txt", time.Time{}, strings.NewReader("0123456789"))
+	}))
+
+	defer server.Close()
+
+	client := fluhttp.NewClient(nil)
+	in := client.ByteRange(server.URL, 2, 5)
+	text := new(flu.PlainText)
+	err := flu.DecodeFrom(in, text)
+	assert.Nil(t, err)
+	assert.Equal(t, "23456", text.Value)
+	assert.Equal(t, int64(10), in.Total())
+
+	in = client.ByteRange(server.URL, 7, 0)
+	err = flu.DecodeFrom(in, text)
+	assert.Nil(t, err)
+	assert.Equal(t, "789", text.Value)
+
+	size, err := in.Size(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), size)
+}
+
+func TestClient_ByteRange_NotSupported(t *testing.T) {
+	server := httptest.NewServer(ConstHandler{
+		StatusCode: http.StatusOK,
+		Response:   "0123456789",
+	})
+
+	defer server.Close()
+
+	err := flu.DecodeFrom(fluhttp.NewClient(nil).ByteRange(server.URL, 2, 5), new(flu.PlainText))
+	assert.IsType(t, fluhttp.ContentRangeError(""), err)
+}
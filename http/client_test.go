@@ -10,6 +10,7 @@ import (
 
 	"github.com/jfk9w-go/flu"
 	fluhttp "github.com/jfk9w-go/flu/http"
+	"github.com/jfk9w-go/flu/serde"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -61,6 +62,87 @@ func TestClient_GET_ResponseJSON(t *testing.T) {
 	assert.Equal(t, "OK", status.Status)
 }
 
+func TestClient_GET_DecodeBodyValue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		writer.Header().Set("Content-Type", "application/x-yaml")
+		writer.WriteHeader(http.StatusOK)
+		_, _ = writer.Write([]byte("status: OK\n"))
+	}))
+
+	defer server.Close()
+
+	type StatusResponse struct {
+		Status string `yaml:"status"`
+	}
+
+	status := new(StatusResponse)
+	err := fluhttp.NewClient(nil).
+		GET(server.URL).
+		Execute().
+		CheckStatus(http.StatusOK).
+		DecodeBodyValue(status).
+		Error
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", status.Status)
+}
+
+func TestClient_GET_LimitBody(t *testing.T) {
+	server := httptest.NewServer(ConstHandler{
+		StatusCode: http.StatusOK,
+		Response:   "0123456789",
+	})
+
+	defer server.Close()
+
+	text := new(flu.PlainText)
+	err := fluhttp.NewClient(nil).
+		GET(server.URL).
+		Execute().
+		LimitBody(serde.Size{Bytes: 5}).
+		DecodeBody(text).
+		Error
+	assert.Equal(t, flu.SizeLimitError{Limit: serde.Size{Bytes: 5}, Seen: 10}, err)
+}
+
+func TestClient_GET_DecodeBodyValidated(t *testing.T) {
+	server := httptest.NewServer(ConstHandler{
+		StatusCode: http.StatusOK,
+		Response:   `{"status": ""}`,
+	})
+
+	defer server.Close()
+
+	type StatusResponse struct {
+		Status string `json:"status" validate:"required"`
+	}
+
+	err := fluhttp.NewClient(nil).
+		GET(server.URL).
+		Execute().
+		DecodeBody(flu.Validated(flu.JSON{Value: new(StatusResponse)})).
+		Error
+	assert.Equal(t, flu.ValidationError{{Path: "status", Rule: "required"}}, err)
+}
+
+func TestClient_GET_DecodeTextBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		writer.Header().Set("Content-Type", "text/html; charset=windows-1251")
+		writer.WriteHeader(http.StatusOK)
+		_, _ = writer.Write([]byte{0xcf, 0xf0, 0xe8, 0xe2, 0xe5, 0xf2})
+	}))
+
+	defer server.Close()
+
+	text := new(flu.PlainText)
+	err := fluhttp.NewClient(nil).
+		GET(server.URL).
+		Execute().
+		DecodeTextBody(text).
+		Error
+	assert.Nil(t, err)
+	assert.Equal(t, "Привет", text.Value)
+}
+
 func TestClient_GET_StatusCodeError(t *testing.T) {
 	server := httptest.NewServer(ConstHandler{
 		StatusCode: http.StatusInternalServerError,
@@ -190,7 +190,7 @@ func (r *Request) do() (*http.Response, error) {
 				}
 			}
 		} else if b, ok := r.body.(flu.EncoderTo); ok {
-			body, err := flu.PipeInput(b).Reader()
+			body, err := flu.ContextReader(r.Request.Context(), flu.PipeInput(b))
 			if err != nil {
 				return nil, err
 			}
@@ -209,7 +209,9 @@ func (r *Request) do() (*http.Response, error) {
 	}
 	if len(r.client.statuses) > 0 {
 		if !r.client.statuses[response.StatusCode] {
-			return nil, NewStatusCodeError(response)
+			err := NewStatusCodeError(response)
+			_ = response.Body.Close()
+			return nil, err
 		}
 	}
 	return response, nil
@@ -0,0 +1,42 @@
+package http
+
+import (
+	"context"
+	"io"
+)
+
+// Resource is a flu.Input which downloads the resource with the Client.
+// The download reuses the Client settings: transport, rate limiter,
+// headers, cookies, authorization and timeout.
+// If the Client has no accepted statuses set (see Client.AcceptStatus),
+// any non-2xx status results in StatusCodeError.
+type Resource struct {
+	// Client is the Client used for requests.
+	Client *Client
+	// URL is the resource URL.
+	URL string
+}
+
+// Resource creates a Resource bound to the Client.
+func (c *Client) Resource(rawurl string) Resource {
+	return Resource{Client: c, URL: rawurl}
+}
+
+func (r Resource) Reader() (io.Reader, error) {
+	return r.ReaderContext(context.Background())
+}
+
+func (r Resource) ReaderContext(ctx context.Context) (io.Reader, error) {
+	resp := r.Client.GET(r.URL).Context(ctx).Execute()
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	if len(r.Client.statuses) == 0 && (resp.StatusCode < 200 || resp.StatusCode > 299) {
+		err := NewStatusCodeError(resp.Response)
+		_ = resp.Body.Close()
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
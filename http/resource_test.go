@@ -0,0 +1,40 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jfk9w-go/flu"
+	fluhttp "github.com/jfk9w-go/flu/http"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Resource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer token" {
+			writer.WriteHeader(http.StatusUnauthorized)
+			_, _ = writer.Write([]byte("unauthorized"))
+			return
+		}
+
+		_, _ = writer.Write([]byte("content"))
+	}))
+
+	defer server.Close()
+
+	text := new(flu.PlainText)
+	err := flu.DecodeFrom(fluhttp.NewClient(nil).Resource(server.URL), text)
+	assert.Equal(t, fluhttp.StatusCodeError{
+		StatusCode:   http.StatusUnauthorized,
+		ResponseBody: flu.Bytes("unauthorized"),
+	}, err)
+
+	client := fluhttp.NewClient(nil).Auth(fluhttp.Bearer("token"))
+	err = flu.DecodeFrom(client.Resource(server.URL), text)
+	assert.Nil(t, err)
+	assert.Equal(t, "content", text.Value)
+
+	err = flu.DecodeFrom(flu.URL(server.URL), text)
+	assert.NotNil(t, err)
+}
@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"mime"
 	"net/http"
 	"strings"
 
 	"github.com/jfk9w-go/flu"
+	"github.com/jfk9w-go/flu/serde"
 )
 
 // Response is a fluent response wrapper.
@@ -71,7 +73,9 @@ func (r *Response) CheckStatus(codes ...int) *Response {
 		}
 	}
 
-	return r.complete(NewStatusCodeError(r.Response))
+	err := NewStatusCodeError(r.Response)
+	_ = r.Body.Close()
+	return r.complete(err)
 }
 
 func (r *Response) CheckContentType(value string) *Response {
@@ -85,7 +89,23 @@ func (r *Response) CheckContentType(value string) *Response {
 	return r
 }
 
+// LimitBody limits the response body size.
+// Reading the body fails with flu.SizeLimitError once the limit is exceeded.
+// If the response Content-Length exceeds the limit, the error is set immediately.
+func (r *Response) LimitBody(limit serde.Size) *Response {
+	if r.Error != nil {
+		return r
+	}
+	if r.ContentLength > limit.Bytes {
+		_ = r.Body.Close()
+		return r.complete(flu.SizeLimitError{Limit: limit, Seen: r.ContentLength})
+	}
+	r.Body = flu.LimitReader(r.Body, limit).(io.ReadCloser)
+	return r
+}
+
 // Decode reads the response body.
+// Wrap the decoder with flu.Validated to validate the decoded value.
 func (r *Response) DecodeBody(decoder flu.DecoderFrom) *Response {
 	if r.Error != nil {
 		return r
@@ -93,6 +113,19 @@ func (r *Response) DecodeBody(decoder flu.DecoderFrom) *Response {
 	return r.complete(flu.DecodeFrom(flu.IO{R: r.Body}, decoder))
 }
 
+// DecodeBodyValue decodes the response body into the value
+// using the codec registered for the response Content-Type in flu.DefaultCodecs.
+func (r *Response) DecodeBodyValue(value interface{}) *Response {
+	if r.Error != nil {
+		return r
+	}
+	codec, err := flu.DefaultCodecs.ByContentType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return r.complete(err)
+	}
+	return r.DecodeBody(codec(value))
+}
+
 type ContentTypeError string
 
 func (e ContentTypeError) Error() string {
@@ -117,6 +150,19 @@ func (r *Response) Reader() (io.Reader, error) {
 	return h.reader, err
 }
 
+// DecodeTextBody decodes the text response body converting it from the charset
+// declared in Content-Type (see flu.AutoChars for detection details).
+func (r *Response) DecodeTextBody(decoder flu.DecoderFrom) *Response {
+	if r.Error != nil {
+		return r
+	}
+	var charset string
+	if _, params, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil {
+		charset = params["charset"]
+	}
+	return r.complete(flu.DecodeFrom(flu.AutoChars{In: flu.IO{R: r.Body}, Charset: charset}, decoder))
+}
+
 func (r *Response) DecodeBodyTo(out flu.Output) *Response {
 	if r.Error != nil {
 		return r
@@ -3,6 +3,8 @@ package flu
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"fmt"
 	"io"
 	"net"
 	"net/http"
@@ -100,6 +102,8 @@ func (f File) Remove() error {
 }
 
 // URL is a read-only resource accessible by URL.
+// It uses http.DefaultClient, so see github.com/jfk9w-go/flu/http.Client.Resource
+// for downloads with timeouts, proxies, authorization, etc.
 type URL string
 
 // Unmask returns the underlying string.
@@ -108,10 +112,22 @@ func (u URL) Unmask() string {
 }
 
 func (u URL) Reader() (io.Reader, error) {
-	resp, err := http.Get(string(u))
+	return u.ReaderContext(context.Background())
+}
+
+func (u URL) ReaderContext(ctx context.Context) (io.Reader, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, string(u), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("get %s: %s", string(u), resp.Status)
+	}
 	return resp.Body, nil
 }
 
@@ -159,23 +175,98 @@ type Conn struct {
 
 	// Address is the address passed to Dialer.Dial.
 	Address string
+
+	// TLS is the TLS client configuration.
+	// If set, the connection is secured with TLS.
+	// May be empty.
+	TLS *tls.Config
+
+	// MaxPacketSize is the maximum datagram size for packet networks
+	// (like "udp" or "unixgram").
+	// If positive, the data written to Writer is buffered and sent
+	// in datagrams of at most MaxPacketSize bytes.
+	// May be empty.
+	MaxPacketSize int
+}
+
+// TCP creates a Conn for the TCP address.
+func TCP(address string) Conn {
+	return Conn{Network: "tcp", Address: address}
+}
+
+// Unix creates a Conn for the Unix domain socket path.
+func Unix(path string) Conn {
+	return Conn{Network: "unix", Address: path}
+}
+
+// UDP creates a Conn for the UDP address
+// sending datagrams of at most maxPacketSize bytes.
+func UDP(address string, maxPacketSize int) Conn {
+	return Conn{Network: "udp", Address: address, MaxPacketSize: maxPacketSize}
 }
 
 // Dial opens a net.Conn using the provided struct fields.
 func (c Conn) Dial() (net.Conn, error) {
 	if c.Context != nil {
-		return c.Dialer.DialContext(c.Context, c.Network, c.Address)
+		return c.dial(c.Context)
 	} else {
-		return c.Dialer.Dial(c.Network, c.Address)
+		return c.dial(context.Background())
 	}
 }
 
+// DialContext opens a net.Conn bound to the provided context
+// (Context field is ignored).
+// The connection is closed as soon as the context is done.
+func (c Conn) DialContext(ctx context.Context) (net.Conn, error) {
+	conn, err := c.dial(ctx)
+	if err != nil || ctx.Done() == nil {
+		return conn, err
+	}
+
+	return contextConn{conn, newContextCloser(ctx, conn)}, nil
+}
+
+func (c Conn) dial(ctx context.Context) (net.Conn, error) {
+	if c.TLS != nil {
+		dialer := &tls.Dialer{NetDialer: &c.Dialer, Config: c.TLS}
+		return dialer.DialContext(ctx, c.Network, c.Address)
+	}
+
+	return c.Dialer.DialContext(ctx, c.Network, c.Address)
+}
+
 func (c Conn) Reader() (io.Reader, error) {
 	return c.Dial()
 }
 
+func (c Conn) ReaderContext(ctx context.Context) (io.Reader, error) {
+	return c.DialContext(ctx)
+}
+
 func (c Conn) Writer() (io.Writer, error) {
-	return c.Dial()
+	conn, err := c.Dial()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.writer(conn), nil
+}
+
+func (c Conn) WriterContext(ctx context.Context) (io.Writer, error) {
+	conn, err := c.DialContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.writer(conn), nil
+}
+
+func (c Conn) writer(conn net.Conn) io.Writer {
+	if c.MaxPacketSize > 0 {
+		return &packetWriter{conn: conn, max: c.MaxPacketSize}
+	}
+
+	return conn
 }
 
 // Close attempts to close the provided value
@@ -188,6 +279,18 @@ func Close(value interface{}) error {
 	}
 }
 
+// CloseWithError attempts to close the provided value
+// passing the error which caused the closing.
+// Values implementing CloseWithError(error) error (like *io.PipeWriter)
+// are able to react to the error, all others are just closed.
+func CloseWithError(value interface{}, err error) error {
+	if closer, ok := value.(interface{ CloseWithError(error) error }); ok {
+		return closer.CloseWithError(err)
+	} else {
+		return Close(value)
+	}
+}
+
 // AnyCloser wraps the provided value with io.Closer interface.
 type AnyCloser struct {
 	V interface{}
@@ -209,19 +312,69 @@ type Chars struct {
 }
 
 func (cs Chars) Reader() (io.Reader, error) {
-	r, err := cs.In.Reader()
+	return cs.ReaderContext(context.Background())
+}
+
+func (cs Chars) ReaderContext(ctx context.Context) (io.Reader, error) {
+	r, err := ContextReader(ctx, cs.In)
 	if err != nil {
 		return nil, err
 	}
 
-	return cs.Enc.NewDecoder().Reader(r), nil
+	return chainReader{cs.Enc.NewDecoder().Reader(r), []interface{}{r}}, nil
 }
 
 func (cs Chars) Writer() (io.Writer, error) {
-	w, err := cs.Out.Writer()
+	return cs.WriterContext(context.Background())
+}
+
+func (cs Chars) WriterContext(ctx context.Context) (io.Writer, error) {
+	w, err := ContextWriter(ctx, cs.Out)
 	if err != nil {
 		return nil, err
 	}
 
-	return cs.Enc.NewEncoder().Writer(w), nil
+	tw := cs.Enc.NewEncoder().Writer(w)
+	return chainWriter{tw, []interface{}{tw, w}}, nil
+}
+
+// chainReader is an io.Reader decorator which closes
+// the underlying values in order.
+type chainReader struct {
+	io.Reader
+	chain []interface{}
+}
+
+func (r chainReader) Close() error {
+	return closeChain(r.chain, nil)
+}
+
+func (r chainReader) CloseWithError(err error) error {
+	return closeChain(r.chain, err)
+}
+
+// chainWriter is an io.Writer decorator which closes
+// the underlying values in order.
+type chainWriter struct {
+	io.Writer
+	chain []interface{}
+}
+
+func (w chainWriter) Close() error {
+	return closeChain(w.chain, nil)
+}
+
+func (w chainWriter) CloseWithError(err error) error {
+	return closeChain(w.chain, err)
+}
+
+func closeChain(chain []interface{}, err error) error {
+	var result error
+	for _, value := range chain {
+		if err := CloseWithError(value, err); err != nil && result == nil {
+			result = err
+		}
+	}
+
+	return result
 }
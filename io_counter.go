@@ -1,18 +1,23 @@
 package flu
 
-import "io"
+import (
+	"context"
+	"io"
+	"sync/atomic"
+)
 
 // Counter is an int64 counter.
+// It is safe for concurrent use.
 type Counter int64
 
 // Value returns the current value of the *Counter.
 func (c *Counter) Value() int64 {
-	return *(*int64)(c)
+	return atomic.LoadInt64((*int64)(c))
 }
 
 // Add adds an int64 value to the counter.
 func (c *Counter) Add(n int64) {
-	*(*int64)(c) += n
+	atomic.AddInt64((*int64)(c), n)
 }
 
 // ReaderCounter is a counting io.Reader.
@@ -26,11 +31,9 @@ func (rc ReaderCounter) Read(data []byte) (n int, err error) {
 	n = len(data)
 	if rc.Reader != nil {
 		n, err = rc.Reader.Read(data)
-		if err != nil {
-			return
-		}
 	}
 
+	// n bytes may be returned along with an error (including io.EOF)
 	rc.Add(int64(n))
 	return
 }
@@ -39,6 +42,10 @@ func (rc ReaderCounter) Close() error {
 	return Close(rc.Reader)
 }
 
+func (rc ReaderCounter) CloseWithError(err error) error {
+	return CloseWithError(rc.Reader, err)
+}
+
 // WriterCounter is a counting io.Writer.
 // Useful for calculating the total size of written data.
 type WriterCounter struct {
@@ -50,19 +57,20 @@ func (wc WriterCounter) Write(data []byte) (n int, err error) {
 	n = len(data)
 	if wc.Writer != nil {
 		n, err = wc.Writer.Write(data)
-		if err != nil {
-			return 0, err
-		}
 	}
 
 	wc.Add(int64(n))
-	return n, nil
+	return n, err
 }
 
 func (wc WriterCounter) Close() error {
 	return Close(wc.Writer)
 }
 
+func (wc WriterCounter) CloseWithError(err error) error {
+	return CloseWithError(wc.Writer, err)
+}
+
 type IOCounter struct {
 	Input
 	Output
@@ -70,8 +78,12 @@ type IOCounter struct {
 }
 
 func (c *IOCounter) Reader() (r io.Reader, err error) {
+	return c.ReaderContext(context.Background())
+}
+
+func (c *IOCounter) ReaderContext(ctx context.Context) (r io.Reader, err error) {
 	if c.Input != nil {
-		r, err = c.Input.Reader()
+		r, err = ContextReader(ctx, c.Input)
 		if err != nil {
 			return nil, err
 		}
@@ -81,8 +93,12 @@ func (c *IOCounter) Reader() (r io.Reader, err error) {
 }
 
 func (c *IOCounter) Writer() (w io.Writer, err error) {
+	return c.WriterContext(context.Background())
+}
+
+func (c *IOCounter) WriterContext(ctx context.Context) (w io.Writer, err error) {
 	if c.Output != nil {
-		w, err = c.Output.Writer()
+		w, err = ContextWriter(ctx, c.Output)
 		if err != nil {
 			return
 		}
@@ -0,0 +1,104 @@
+package flu
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// valueSink accepts a sequence of decoded values.
+// Supported targets are a pointer to slice (values are appended),
+// a channel (values are sent, the channel is closed on completion)
+// and a func(T) error callback.
+type valueSink struct {
+	target   reflect.Value
+	elemType reflect.Type
+}
+
+func newValueSink(target interface{}) (*valueSink, error) {
+	value := reflect.ValueOf(target)
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.Elem().Kind() == reflect.Slice {
+			return &valueSink{value.Elem(), value.Type().Elem().Elem()}, nil
+		}
+	case reflect.Chan:
+		if value.Type().ChanDir()&reflect.SendDir != 0 {
+			return &valueSink{value, value.Type().Elem()}, nil
+		}
+	case reflect.Func:
+		fun := value.Type()
+		if fun.NumIn() == 1 && fun.NumOut() == 1 && fun.Out(0) == errorType {
+			return &valueSink{value, fun.In(0)}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported value type: %T (expected slice pointer, channel or func(T) error)", target)
+}
+
+// New returns a pointer to a new zero element.
+func (s *valueSink) New() reflect.Value {
+	return reflect.New(s.elemType)
+}
+
+// Put accepts the element pointed to by ptr.
+func (s *valueSink) Put(ptr reflect.Value) error {
+	elem := ptr.Elem()
+	switch s.target.Kind() {
+	case reflect.Slice:
+		s.target.Set(reflect.Append(s.target, elem))
+	case reflect.Chan:
+		s.target.Send(elem)
+	case reflect.Func:
+		if err := s.target.Call([]reflect.Value{elem})[0].Interface(); err != nil {
+			return err.(error)
+		}
+	}
+
+	return nil
+}
+
+// Close closes the target channel (if any).
+func (s *valueSink) Close() {
+	if s.target.Kind() == reflect.Chan {
+		s.target.Close()
+	}
+}
+
+// forEachValue iterates over a slice, an array or a channel
+// (until it is closed).
+func forEachValue(source interface{}, fun func(value reflect.Value) error) error {
+	value := reflect.ValueOf(source)
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := fun(value.Index(i)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case reflect.Chan:
+		if value.Type().ChanDir()&reflect.RecvDir == 0 {
+			break
+		}
+
+		for {
+			elem, ok := value.Recv()
+			if !ok {
+				return nil
+			}
+
+			if err := fun(elem); err != nil {
+				return err
+			}
+		}
+	}
+
+	return fmt.Errorf("unsupported value type: %T (expected slice, array or channel)", source)
+}
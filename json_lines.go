@@ -0,0 +1,94 @@
+package flu
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"reflect"
+
+	"github.com/jfk9w-go/flu/serde"
+)
+
+// JSONLines encodes/decodes a sequence of values using JSON Lines (NDJSON) format.
+// Records are processed one by one without buffering the whole stream.
+//
+// For encoding, Value may be a slice, an array or a channel
+// (which is read until closed).
+// For decoding, Value may be a pointer to slice (records are appended),
+// a channel (which is closed after decoding) or a func(T) error callback
+// (a callback error stops decoding and is returned as is).
+// Records which can not be read or decoded are reported as JSONLineError.
+type JSONLines struct {
+	Value interface{}
+	// AllowTruncated makes the decoder silently skip the last line
+	// if it is not terminated with a newline and can not be decoded.
+	AllowTruncated bool
+	// MaxRecordSize is the maximum line size.
+	// Lines are not limited if zero.
+	MaxRecordSize serde.Size
+}
+
+func (j JSONLines) EncodeTo(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	return forEachValue(j.Value, func(value reflect.Value) error {
+		return encoder.Encode(value.Interface())
+	})
+}
+
+func (j JSONLines) DecodeFrom(r io.Reader) error {
+	sink, err := newValueSink(j.Value)
+	if err != nil {
+		return err
+	}
+
+	defer sink.Close()
+	var truncated bool
+	split := func(data []byte, atEOF bool) (int, []byte, error) {
+		// the last line is returned at EOF without a terminating newline
+		truncated = atEOF && len(data) > 0 && bytes.IndexByte(data, '\n') < 0
+		return bufio.ScanLines(data, atEOF)
+	}
+
+	maxRecordSize := j.MaxRecordSize
+	if maxRecordSize.Bytes <= 0 {
+		maxRecordSize.Bytes = math.MaxInt - 1
+	}
+
+	var putErr error
+	err = Scanner{Split: split, MaxRecordSize: maxRecordSize}.scan(r, func(line int, record []byte) error {
+		if record = bytes.TrimSpace(record); len(record) == 0 {
+			return nil
+		}
+
+		ptr := sink.New()
+		if err := json.Unmarshal(record, ptr.Interface()); err != nil {
+			if truncated && j.AllowTruncated {
+				return ErrStopScan
+			}
+
+			return err
+		}
+
+		if putErr = sink.Put(ptr); putErr != nil {
+			return ErrStopScan
+		}
+
+		return nil
+	})
+
+	if putErr != nil {
+		return putErr
+	}
+
+	return err
+}
+
+func (j JSONLines) ContentType() string {
+	return "application/x-ndjson"
+}
+
+// JSONLineError is returned when a JSON Lines record can not be read or decoded.
+// It is the same type as ScanError.
+type JSONLineError = ScanError
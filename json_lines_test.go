@@ -0,0 +1,84 @@
+package flu_test
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jfk9w-go/flu"
+	"github.com/jfk9w-go/flu/serde"
+	"github.com/stretchr/testify/assert"
+)
+
+type jsonLinesRecord struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestJSONLines_EncodeTo(t *testing.T) {
+	records := make(chan jsonLinesRecord, 2)
+	records <- jsonLinesRecord{ID: 1, Name: "a"}
+	records <- jsonLinesRecord{ID: 2, Name: "b"}
+	close(records)
+
+	buf := new(flu.ByteBuffer)
+	err := flu.EncodeTo(flu.JSONLines{Value: records}, buf)
+	assert.Nil(t, err)
+	assert.Equal(t, "{\"id\":1,\"name\":\"a\"}\n{\"id\":2,\"name\":\"b\"}\n", buf.Unmask().String())
+}
+
+func TestJSONLines_DecodeFrom(t *testing.T) {
+	input := flu.Bytes("{\"id\":1,\"name\":\"a\"}\n\n{\"id\":2,\"name\":\"b\"}")
+	var records []jsonLinesRecord
+	err := flu.DecodeFrom(input, flu.JSONLines{Value: &records})
+	assert.Nil(t, err)
+	assert.Equal(t, []jsonLinesRecord{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}, records)
+}
+
+func TestJSONLines_DecodeFrom_Truncated(t *testing.T) {
+	input := flu.Bytes("{\"id\":1,\"name\":\"a\"}\n{\"id\":2,\"na")
+	var records []jsonLinesRecord
+	err := flu.DecodeFrom(input, flu.JSONLines{Value: &records})
+	assert.IsType(t, flu.JSONLineError{}, err)
+	assert.Equal(t, 2, err.(flu.JSONLineError).Line)
+
+	records = nil
+	err = flu.DecodeFrom(input, flu.JSONLines{Value: &records, AllowTruncated: true})
+	assert.Nil(t, err)
+	assert.Equal(t, []jsonLinesRecord{{ID: 1, Name: "a"}}, records)
+}
+
+func TestJSONLines_DecodeFrom_Callback(t *testing.T) {
+	input := flu.Bytes("{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n")
+	stop := errors.New("stop")
+	var ids []int
+	err := flu.DecodeFrom(input, flu.JSONLines{Value: func(record jsonLinesRecord) error {
+		ids = append(ids, record.ID)
+		if record.ID == 2 {
+			return stop
+		}
+
+		return nil
+	}})
+
+	assert.Equal(t, stop, err)
+	assert.Equal(t, []int{1, 2}, ids)
+}
+
+func TestJSONLines_DecodeFrom_MaxRecordSize(t *testing.T) {
+	input := flu.Bytes("{\"id\":1}\n{\"id\":2,\"name\":\"" + strings.Repeat("a", 100) + "\"}\n")
+	var records []jsonLinesRecord
+	err := flu.DecodeFrom(input, flu.JSONLines{Value: &records, MaxRecordSize: serde.Size{Bytes: 64}})
+	assert.Equal(t, flu.ScanError{Line: 2, Err: bufio.ErrTooLong}, err)
+	assert.Equal(t, []jsonLinesRecord{{ID: 1}}, records)
+}
+
+func TestJSONLines_DecodeFrom_Unlimited(t *testing.T) {
+	name := strings.Repeat("a", 100<<10)
+	input := flu.Bytes("{\"id\":1,\"name\":\"" + name + "\"}\n")
+	var records []jsonLinesRecord
+	err := flu.DecodeFrom(input, flu.JSONLines{Value: &records})
+	assert.Nil(t, err)
+	assert.Equal(t, []jsonLinesRecord{{ID: 1, Name: name}}, records)
+}
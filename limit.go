@@ -0,0 +1,126 @@
+package flu
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/jfk9w-go/flu/serde"
+)
+
+// SizeLimitError is returned when the data size exceeds the limit.
+type SizeLimitError struct {
+	// Limit is the size limit.
+	Limit serde.Size
+	// Seen is the number of bytes seen so far (greater than Limit).
+	Seen int64
+}
+
+func (e SizeLimitError) Error() string {
+	return fmt.Sprintf("size limit of %s exceeded (seen %d bytes)", e.Limit, e.Seen)
+}
+
+// LimitInput wraps the Input so that reading fails with SizeLimitError
+// once more than limit bytes are read.
+// Unlike io.LimitReader, the data is never silently truncated.
+func LimitInput(in Input, limit serde.Size) Input {
+	return limitInput{in, limit}
+}
+
+type limitInput struct {
+	in    Input
+	limit serde.Size
+}
+
+func (li limitInput) Reader() (io.Reader, error) {
+	r, err := li.in.Reader()
+	if err != nil {
+		return nil, err
+	}
+
+	return LimitReader(r, li.limit), nil
+}
+
+// LimitReader wraps the io.Reader so that reading fails with SizeLimitError
+// once more than limit bytes are read.
+// Closing the returned io.Reader closes the underlying one.
+func LimitReader(r io.Reader, limit serde.Size) io.Reader {
+	return &limitReader{r: r, limit: limit}
+}
+
+type limitReader struct {
+	r     io.Reader
+	limit serde.Size
+	seen  int64
+}
+
+func (lr *limitReader) Read(data []byte) (int, error) {
+	if lr.seen > lr.limit.Bytes {
+		return 0, SizeLimitError{Limit: lr.limit, Seen: lr.seen}
+	}
+
+	// read at most one byte past the limit to detect the overflow
+	if max := lr.limit.Bytes - lr.seen + 1; int64(len(data)) > max {
+		data = data[:max]
+	}
+
+	n, err := lr.r.Read(data)
+	lr.seen += int64(n)
+	if lr.seen > lr.limit.Bytes {
+		return n - int(lr.seen-lr.limit.Bytes), SizeLimitError{Limit: lr.limit, Seen: lr.seen}
+	}
+
+	return n, err
+}
+
+func (lr *limitReader) Close() error {
+	return Close(lr.r)
+}
+
+func (lr *limitReader) CloseWithError(err error) error {
+	return CloseWithError(lr.r, err)
+}
+
+// LimitOutput wraps the Output so that writing fails with SizeLimitError
+// once more than limit bytes are written.
+// The write exceeding the limit is rejected as a whole.
+func LimitOutput(out Output, limit serde.Size) Output {
+	return limitOutput{out, limit}
+}
+
+type limitOutput struct {
+	out   Output
+	limit serde.Size
+}
+
+func (lo limitOutput) Writer() (io.Writer, error) {
+	w, err := lo.out.Writer()
+	if err != nil {
+		return nil, err
+	}
+
+	return &limitWriter{w: w, limit: lo.limit}, nil
+}
+
+type limitWriter struct {
+	w       io.Writer
+	limit   serde.Size
+	written int64
+}
+
+func (lw *limitWriter) Write(data []byte) (int, error) {
+	if seen := lw.written + int64(len(data)); seen > lw.limit.Bytes {
+		return 0, SizeLimitError{Limit: lw.limit, Seen: seen}
+	}
+
+	n, err := lw.w.Write(data)
+	lw.written += int64(n)
+	return n, err
+}
+
+func (lw *limitWriter) Close() error {
+	return Close(lw.w)
+}
+
+func (lw *limitWriter) CloseWithError(err error) error {
+	return CloseWithError(lw.w, err)
+}
@@ -0,0 +1,30 @@
+package flu_test
+
+import (
+	"testing"
+
+	"github.com/jfk9w-go/flu"
+	"github.com/jfk9w-go/flu/serde"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitInput(t *testing.T) {
+	text := new(flu.PlainText)
+	err := flu.DecodeFrom(flu.LimitInput(flu.Bytes("12345"), serde.Size{Bytes: 5}), text)
+	assert.Nil(t, err)
+	assert.Equal(t, "12345", text.Value)
+
+	err = flu.DecodeFrom(flu.LimitInput(flu.Bytes("123456789"), serde.Size{Bytes: 5}), text)
+	assert.Equal(t, flu.SizeLimitError{Limit: serde.Size{Bytes: 5}, Seen: 6}, err)
+}
+
+func TestLimitOutput(t *testing.T) {
+	buf := new(flu.ByteBuffer)
+	err := flu.EncodeTo(&flu.PlainText{Value: "12345"}, flu.LimitOutput(buf, serde.Size{Bytes: 5}))
+	assert.Nil(t, err)
+	assert.Equal(t, "12345", buf.Unmask().String())
+
+	err = flu.EncodeTo(&flu.PlainText{Value: "123456"}, flu.LimitOutput(buf, serde.Size{Bytes: 5}))
+	assert.Equal(t, flu.SizeLimitError{Limit: serde.Size{Bytes: 5}, Seen: 6}, err)
+	assert.Equal(t, "", buf.Unmask().String())
+}
@@ -0,0 +1,241 @@
+package flu
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+var errNotDir = errors.New("not a directory")
+
+// MemFS is an in-memory FS implementation.
+// It is safe for concurrent use.
+type MemFS struct {
+	nodes map[string]*memNode
+	clock Clock
+	mu    RWMutex
+}
+
+// NewMemFS creates an empty MemFS.
+func NewMemFS() *MemFS {
+	fsys := &MemFS{
+		nodes: make(map[string]*memNode),
+		clock: DefaultClock,
+	}
+
+	fsys.nodes["."] = &memNode{name: ".", dir: true, modTime: fsys.clock.Now()}
+	return fsys
+}
+
+type memNode struct {
+	name    string
+	dir     bool
+	data    []byte
+	modTime time.Time
+}
+
+func (n *memNode) info() fs.FileInfo {
+	return memFileInfo{name: n.name, dir: n.dir, size: int64(len(n.data)), modTime: n.modTime}
+}
+
+type memFileInfo struct {
+	name    string
+	dir     bool
+	size    int64
+	modTime time.Time
+}
+
+func (i memFileInfo) Name() string       { return i.name }
+func (i memFileInfo) Size() int64        { return i.size }
+func (i memFileInfo) ModTime() time.Time { return i.modTime }
+func (i memFileInfo) IsDir() bool        { return i.dir }
+func (i memFileInfo) Sys() interface{}   { return nil }
+
+func (i memFileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0755
+	}
+
+	return 0644
+}
+
+func (m *MemFS) Open(name string) (io.ReadCloser, error) {
+	defer m.mu.RLock().Unlock()
+	node, ok := m.nodes[slashPath(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	if node.dir {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	return io.NopCloser(bytes.NewReader(node.data)), nil
+}
+
+func (m *MemFS) Create(name string) (io.WriteCloser, error) {
+	defer m.mu.Lock().Unlock()
+	key := slashPath(name)
+	if parent, ok := m.nodes[path.Dir(key)]; !ok {
+		return nil, &fs.PathError{Op: "create", Path: name, Err: fs.ErrNotExist}
+	} else if !parent.dir {
+		return nil, &fs.PathError{Op: "create", Path: name, Err: errNotDir}
+	}
+
+	node, ok := m.nodes[key]
+	if ok && node.dir {
+		return nil, &fs.PathError{Op: "create", Path: name, Err: fs.ErrExist}
+	}
+
+	node = &memNode{name: path.Base(key), modTime: m.clock.Now()}
+	m.nodes[key] = node
+	return &memWriter{fs: m, node: node}, nil
+}
+
+type memWriter struct {
+	fs   *MemFS
+	node *memNode
+}
+
+func (w *memWriter) Write(data []byte) (int, error) {
+	defer w.fs.mu.Lock().Unlock()
+	w.node.data = append(w.node.data, data...)
+	w.node.modTime = w.fs.clock.Now()
+	return len(data), nil
+}
+
+func (w *memWriter) Close() error {
+	return nil
+}
+
+func (m *MemFS) Stat(name string) (fs.FileInfo, error) {
+	defer m.mu.RLock().Unlock()
+	node, ok := m.nodes[slashPath(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return node.info(), nil
+}
+
+func (m *MemFS) Remove(name string) error {
+	defer m.mu.Lock().Unlock()
+	key := slashPath(name)
+	for child := range m.nodes {
+		if isMemChild(key, child) {
+			delete(m.nodes, child)
+		}
+	}
+
+	if key != "." {
+		delete(m.nodes, key)
+	}
+
+	return nil
+}
+
+func (m *MemFS) MkdirAll(name string) error {
+	defer m.mu.Lock().Unlock()
+	key := slashPath(name)
+	if key == "." {
+		return nil
+	}
+
+	current := ""
+	for _, part := range strings.Split(key, "/") {
+		if current == "" {
+			current = part
+		} else {
+			current += "/" + part
+		}
+
+		if node, ok := m.nodes[current]; ok {
+			if !node.dir {
+				return &fs.PathError{Op: "mkdir", Path: name, Err: errNotDir}
+			}
+
+			continue
+		}
+
+		m.nodes[current] = &memNode{name: part, dir: true, modTime: m.clock.Now()}
+	}
+
+	return nil
+}
+
+func (m *MemFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	defer m.mu.RLock().Unlock()
+	key := slashPath(name)
+	node, ok := m.nodes[key]
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	if !node.dir {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errNotDir}
+	}
+
+	var entries []fs.DirEntry
+	for child, node := range m.nodes {
+		if child != "." && path.Dir(child) == key {
+			entries = append(entries, fs.FileInfoToDirEntry(node.info()))
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+func (m *MemFS) Rename(oldname, newname string) error {
+	defer m.mu.Lock().Unlock()
+	oldkey, newkey := slashPath(oldname), slashPath(newname)
+	node, ok := m.nodes[oldkey]
+	if !ok {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: fs.ErrNotExist}
+	}
+
+	if parent, ok := m.nodes[path.Dir(newkey)]; !ok || !parent.dir {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: fs.ErrNotExist}
+	}
+
+	if oldkey == newkey {
+		return nil
+	}
+
+	if node.dir && isMemChild(oldkey, newkey) {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: fs.ErrInvalid}
+	}
+
+	for child := range m.nodes {
+		if isMemChild(newkey, child) {
+			delete(m.nodes, child)
+		}
+	}
+
+	for child, childNode := range m.nodes {
+		if isMemChild(oldkey, child) {
+			delete(m.nodes, child)
+			m.nodes[newkey+strings.TrimPrefix(child, oldkey)] = childNode
+		}
+	}
+
+	delete(m.nodes, oldkey)
+	node.name = path.Base(newkey)
+	m.nodes[newkey] = node
+	return nil
+}
+
+// isMemChild checks if child is a descendant of parent.
+func isMemChild(parent, child string) bool {
+	if parent == "." {
+		return child != "."
+	}
+
+	return strings.HasPrefix(child, parent+"/")
+}
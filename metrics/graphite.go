@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"net"
 	"strconv"
 	"strings"
 	"time"
@@ -121,7 +122,7 @@ func (h GraphiteHistogram) Write(b *strings.Builder, now string, key string) {
 type GraphiteClient struct {
 	HistogramBucketFormat string
 
-	address string
+	conn    *flu.PersistentConn
 	prefix  string
 	metrics map[string]GraphiteMetric
 	cancel  func()
@@ -134,10 +135,14 @@ func NewGraphiteClient(address string, interval time.Duration) *GraphiteClient {
 	ctx := context.Background()
 	client := &GraphiteClient{
 		HistogramBucketFormat: "%.2f",
-		address:               address,
 		metrics:               make(map[string]GraphiteMetric),
 		mu:                    new(flu.RWMutex),
 		work:                  new(flu.WaitGroup),
+		conn: &flu.PersistentConn{Conn: flu.Conn{
+			Dialer:  net.Dialer{Timeout: GraphiteTimeout},
+			Network: "tcp",
+			Address: address,
+		}},
 	}
 
 	if interval > 0 {
@@ -173,6 +178,9 @@ func (g *GraphiteClient) Close() {
 	}
 
 	g.work.Wait()
+	if err := g.conn.Close(); err != nil {
+		log.Printf("Failed to close Graphite connection: %s", err)
+	}
 }
 
 func (g *GraphiteClient) Flush(now time.Time) error {
@@ -189,11 +197,8 @@ func (g *GraphiteClient) Flush(now time.Time) error {
 		return nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), GraphiteTimeout)
-	defer cancel()
 	data := &flu.PlainText{Value: b.String()}
-	conn := flu.Conn{Context: ctx, Network: "tcp", Address: g.address}
-	if err := flu.EncodeTo(data, conn); err != nil {
+	if err := flu.EncodeTo(data, g.conn); err != nil {
 		return errors.Wrap(err, "write")
 	}
 
@@ -0,0 +1,187 @@
+package flu
+
+import (
+	"io"
+	"strings"
+)
+
+// MultiError aggregates multiple errors.
+type MultiError []error
+
+func (e MultiError) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the aggregated errors.
+func (e MultiError) Unwrap() []error {
+	return e
+}
+
+// Collect appends the error to MultiError if it is not nil.
+func (e *MultiError) Collect(err error) {
+	if err != nil {
+		*e = append(*e, err)
+	}
+}
+
+// Reduce returns nil if there are no errors,
+// the single error if there is only one,
+// and the MultiError itself otherwise.
+func (e MultiError) Reduce() error {
+	switch len(e) {
+	case 0:
+		return nil
+	case 1:
+		return e[0]
+	default:
+		return e
+	}
+}
+
+// MultiOutput duplicates writes to all of the Outputs.
+// Closing the io.Writer closes all of the underlying io.Writers.
+type MultiOutput []Output
+
+func (mo MultiOutput) Writer() (io.Writer, error) {
+	writers := make([]io.Writer, 0, len(mo))
+	for _, out := range mo {
+		w, err := out.Writer()
+		if err != nil {
+			_ = multiWriter{writers: writers}.CloseWithError(err)
+			return nil, err
+		}
+
+		writers = append(writers, w)
+	}
+
+	return multiWriter{io.MultiWriter(writers...), writers}, nil
+}
+
+type multiWriter struct {
+	io.Writer
+	writers []io.Writer
+}
+
+func (w multiWriter) Close() error {
+	return w.CloseWithError(nil)
+}
+
+func (w multiWriter) CloseWithError(err error) error {
+	var errs MultiError
+	for _, writer := range w.writers {
+		errs.Collect(CloseWithError(writer, err))
+	}
+
+	return errs.Reduce()
+}
+
+// MultiInput concatenates the Inputs.
+// Each Input is opened only when the previous one has been read to the end.
+type MultiInput []Input
+
+func (mi MultiInput) Reader() (io.Reader, error) {
+	return &multiReader{inputs: mi}, nil
+}
+
+type multiReader struct {
+	inputs  []Input
+	current io.Reader
+}
+
+func (r *multiReader) Read(data []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.inputs) == 0 {
+				return 0, io.EOF
+			}
+
+			current, err := r.inputs[0].Reader()
+			if err != nil {
+				return 0, err
+			}
+
+			r.current = current
+			r.inputs = r.inputs[1:]
+		}
+
+		n, err := r.current.Read(data)
+		if err == io.EOF {
+			err = Close(r.current)
+			r.current = nil
+			if n > 0 || err != nil {
+				return n, err
+			}
+
+			continue
+		}
+
+		return n, err
+	}
+}
+
+func (r *multiReader) Close() error {
+	return r.CloseWithError(nil)
+}
+
+func (r *multiReader) CloseWithError(err error) error {
+	r.inputs = nil
+	if r.current != nil {
+		current := r.current
+		r.current = nil
+		return CloseWithError(current, err)
+	}
+
+	return nil
+}
+
+// TeeInput mirrors everything read from the Input to the Output.
+// Closing the io.Reader copies the unread rest of the Input
+// to the Output and closes both of them.
+type TeeInput struct {
+	// In is the Input to be read.
+	In Input
+	// Out is the Output receiving the data.
+	Out Output
+}
+
+func (t TeeInput) Reader() (io.Reader, error) {
+	r, err := t.In.Reader()
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := t.Out.Writer()
+	if err != nil {
+		_ = CloseWithError(r, err)
+		return nil, err
+	}
+
+	return teeReader{io.TeeReader(r, w), r, w}, nil
+}
+
+type teeReader struct {
+	io.Reader
+	r io.Reader
+	w io.Writer
+}
+
+func (t teeReader) Close() error {
+	return t.CloseWithError(nil)
+}
+
+func (t teeReader) CloseWithError(err error) error {
+	if err == nil {
+		_, err = io.Copy(t.w, t.r)
+	}
+
+	var errs MultiError
+	errs.Collect(err)
+	errs.Collect(CloseWithError(t.r, err))
+	errs.Collect(CloseWithError(t.w, err))
+	return errs.Reduce()
+}
@@ -0,0 +1,59 @@
+package flu_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/jfk9w-go/flu"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingOutput struct {
+	err error
+}
+
+func (o failingOutput) Writer() (io.Writer, error) {
+	return o, nil
+}
+
+func (o failingOutput) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+func (o failingOutput) Close() error {
+	return o.err
+}
+
+func TestMultiOutput(t *testing.T) {
+	a, b := new(flu.ByteBuffer), new(flu.ByteBuffer)
+	err := flu.EncodeTo(&flu.PlainText{Value: "test"}, flu.MultiOutput{a, b})
+	assert.Nil(t, err)
+	assert.Equal(t, "test", a.Unmask().String())
+	assert.Equal(t, "test", b.Unmask().String())
+
+	errA, errB := errors.New("a"), errors.New("b")
+	err = flu.EncodeTo(&flu.PlainText{Value: "test"}, flu.MultiOutput{failingOutput{errA}, a, failingOutput{errB}})
+	assert.Equal(t, flu.MultiError{errA, errB}, err)
+	assert.Equal(t, "a; b", err.Error())
+}
+
+func TestMultiInput(t *testing.T) {
+	text := new(flu.PlainText)
+	err := flu.DecodeFrom(flu.MultiInput{flu.Bytes("a"), flu.Bytes(""), flu.Bytes("bc")}, text)
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", text.Value)
+}
+
+func TestTeeInput(t *testing.T) {
+	type Value struct {
+		Name string `json:"name"`
+	}
+
+	mirror := new(flu.ByteBuffer)
+	value := new(Value)
+	err := flu.DecodeFrom(flu.TeeInput{In: flu.Bytes("{\"name\": \"test\"}\n"), Out: mirror}, flu.JSON{Value: value})
+	assert.Nil(t, err)
+	assert.Equal(t, "test", value.Name)
+	assert.Equal(t, "{\"name\": \"test\"}\n", mirror.Unmask().String())
+}
@@ -0,0 +1,143 @@
+package flu
+
+import (
+	"context"
+	"time"
+)
+
+// Progress is a snapshot of a data transfer progress.
+type Progress struct {
+	// Bytes is the number of bytes transferred so far.
+	Bytes int64
+	// Total is the total number of bytes (0 if unknown).
+	Total int64
+	// Elapsed is the time elapsed since the start.
+	Elapsed time.Duration
+	// Rate is the transfer rate (in bytes per second) since the previous report.
+	Rate float64
+	// AverageRate is the transfer rate (in bytes per second) since the start.
+	AverageRate float64
+	// ETA is the estimated time left (negative if unknown).
+	ETA time.Duration
+}
+
+// Done checks if the total number of bytes has been transferred.
+func (p Progress) Done() bool {
+	return p.Total > 0 && p.Bytes >= p.Total
+}
+
+// Percent returns the completion percentage (negative if unknown).
+func (p Progress) Percent() float64 {
+	if p.Total <= 0 {
+		return -1
+	}
+
+	return 100 * float64(p.Bytes) / float64(p.Total)
+}
+
+// ProgressReporter periodically reports the progress of a Counter
+// (for example, of an IOCounter).
+type ProgressReporter struct {
+	// Counter is the transferred bytes counter.
+	Counter *Counter
+	// Total is the total number of bytes (like http.Response.ContentLength).
+	// Zero or negative value means the total is unknown.
+	Total int64
+	// Interval is the reporting interval.
+	// Defaults to DefaultProgressInterval.
+	Interval time.Duration
+	// Clock is used to measure the elapsed time.
+	// DefaultClock is used if nil.
+	Clock Clock
+}
+
+// DefaultProgressInterval is the ProgressReporter interval used when Interval is not set.
+const DefaultProgressInterval = time.Second
+
+// Run reports the progress every Interval until the context is done
+// or the total number of bytes is transferred.
+// The final progress is always reported before returning.
+func (p ProgressReporter) Run(ctx context.Context, report func(Progress)) {
+	interval := p.Interval
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+
+	clock := p.Clock
+	if clock == nil {
+		clock = DefaultClock
+	}
+
+	start := clock.Now()
+	last := Progress{ETA: -1}
+	lastTime := start
+	next := func() Progress {
+		now := clock.Now()
+		progress := Progress{
+			Bytes:   p.Counter.Value(),
+			Total:   p.Total,
+			Elapsed: now.Sub(start),
+			ETA:     -1,
+		}
+
+		if elapsed := now.Sub(lastTime).Seconds(); elapsed > 0 {
+			progress.Rate = float64(progress.Bytes-last.Bytes) / elapsed
+		}
+
+		if elapsed := progress.Elapsed.Seconds(); elapsed > 0 {
+			progress.AverageRate = float64(progress.Bytes) / elapsed
+		}
+
+		if progress.Total > 0 && progress.AverageRate > 0 {
+			left := float64(progress.Total-progress.Bytes) / progress.AverageRate
+			if left < 0 {
+				left = 0
+			}
+
+			progress.ETA = time.Duration(left * float64(time.Second))
+		}
+
+		last, lastTime = progress, now
+		return progress
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			report(next())
+			return
+		case <-ticker.C:
+			progress := next()
+			report(progress)
+			if progress.Done() {
+				return
+			}
+		}
+	}
+}
+
+// Start runs the reporter in the background.
+// The returned channel is closed after the final progress is reported.
+// If the channel is not read in time, stale reports are replaced by newer ones.
+func (p ProgressReporter) Start(ctx context.Context) <-chan Progress {
+	reports := make(chan Progress, 1)
+	go func() {
+		defer close(reports)
+		p.Run(ctx, func(progress Progress) {
+			select {
+			case reports <- progress:
+			default:
+				select {
+				case <-reports:
+				default:
+				}
+
+				reports <- progress
+			}
+		})
+	}()
+
+	return reports
+}
@@ -0,0 +1,138 @@
+package flu_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/jfk9w-go/flu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounter_Concurrent(t *testing.T) {
+	counter := new(flu.Counter)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				counter.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+	assert.Equal(t, int64(10000), counter.Value())
+}
+
+func TestProgressReporter(t *testing.T) {
+	now := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	counter := new(flu.IOCounter)
+	reporter := flu.ProgressReporter{
+		Counter:  &counter.Counter,
+		Total:    100,
+		Interval: 10 * time.Millisecond,
+		Clock: flu.ClockFunc(func() time.Time {
+			now = now.Add(time.Second)
+			return now
+		}),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	reports := reporter.Start(ctx)
+	counter.Counter.Add(50)
+	time.Sleep(30 * time.Millisecond)
+	counter.Counter.Add(50)
+
+	var last flu.Progress
+	for progress := range reports {
+		last = progress
+	}
+
+	assert.True(t, last.Done())
+	assert.Equal(t, int64(100), last.Bytes)
+	assert.Equal(t, float64(100), last.Percent())
+	assert.Equal(t, time.Duration(0), last.ETA)
+	assert.True(t, last.AverageRate > 0)
+	assert.Equal(t, last.Elapsed, now.Sub(time.Date(2020, 5, 1, 0, 0, 1, 0, time.UTC)))
+	assert.Nil(t, ctx.Err())
+}
+
+func TestReaderCounter_CountsBytesReturnedWithError(t *testing.T) {
+	body := strings.Repeat("x", 1000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	defer resp.Body.Close()
+	counter := new(flu.Counter)
+	data, err := ioutil.ReadAll(flu.ReaderCounter{Reader: resp.Body, Counter: counter})
+	assert.NoError(t, err)
+	assert.Equal(t, body, string(data))
+	assert.Equal(t, resp.ContentLength, counter.Value())
+
+	counter = new(flu.Counter)
+	data, err = ioutil.ReadAll(flu.ReaderCounter{Reader: iotest.DataErrReader(strings.NewReader(body)), Counter: counter})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(body)), counter.Value())
+}
+
+func TestProgressReporter_DefaultInterval(t *testing.T) {
+	counter := new(flu.Counter)
+	counter.Add(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var reports []flu.Progress
+	flu.ProgressReporter{Counter: counter}.Run(ctx, func(progress flu.Progress) {
+		reports = append(reports, progress)
+	})
+
+	if assert.Len(t, reports, 1) {
+		assert.Equal(t, int64(10), reports[0].Bytes)
+	}
+}
+
+func TestProgressReporter_Clock(t *testing.T) {
+	now := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	clock := flu.ClockFunc(func() time.Time {
+		defer func() { now = now.Add(2 * time.Second) }()
+		return now
+	})
+
+	counter := new(flu.Counter)
+	counter.Add(50)
+	var reports []flu.Progress
+	flu.ProgressReporter{
+		Counter:  counter,
+		Total:    200,
+		Interval: time.Millisecond,
+		Clock:    clock,
+	}.Run(context.Background(), func(progress flu.Progress) {
+		reports = append(reports, progress)
+		counter.Add(progress.Bytes)
+	})
+
+	assert.Equal(t, []flu.Progress{
+		{Bytes: 50, Total: 200, Elapsed: 2 * time.Second, Rate: 25, AverageRate: 25, ETA: 6 * time.Second},
+		{Bytes: 100, Total: 200, Elapsed: 4 * time.Second, Rate: 25, AverageRate: 25, ETA: 4 * time.Second},
+		{Bytes: 200, Total: 200, Elapsed: 6 * time.Second, Rate: 50, AverageRate: float64(200) / 6, ETA: 0},
+	}, reports)
+}
@@ -0,0 +1,66 @@
+package flu
+
+import (
+	"errors"
+	"io"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+)
+
+var errNoProtoMessage = errors.New("value is not a proto.Message")
+
+// Protobuf encodes/decodes the provided proto.Message using Protocol Buffers format.
+type Protobuf struct {
+	Value proto.Message
+	// JSON enables the canonical JSON wire format instead of the binary one.
+	JSON bool
+}
+
+func (p Protobuf) EncodeTo(w io.Writer) error {
+	if p.Value == nil {
+		return errNoProtoMessage
+	}
+
+	if p.JSON {
+		return new(jsonpb.Marshaler).Marshal(w, p.Value)
+	}
+
+	data, err := proto.Marshal(p.Value)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+func (p Protobuf) DecodeFrom(r io.Reader) error {
+	if p.Value == nil {
+		return errNoProtoMessage
+	}
+
+	if p.JSON {
+		return jsonpb.Unmarshal(r, p.Value)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return proto.Unmarshal(data, p.Value)
+}
+
+func (p Protobuf) ContentType() string {
+	if p.JSON {
+		return "application/json"
+	}
+
+	return "application/x-protobuf"
+}
+
+func protobufCodec(value interface{}) Codec {
+	message, _ := value.(proto.Message)
+	return Protobuf{Value: message}
+}
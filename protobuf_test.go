@@ -0,0 +1,39 @@
+package flu_test
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/jfk9w-go/flu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProtobuf(t *testing.T) {
+	for _, json := range []bool{false, true} {
+		buf := new(flu.ByteBuffer)
+		err := flu.EncodeTo(flu.Protobuf{Value: &wrappers.StringValue{Value: "test"}, JSON: json}, buf)
+		assert.Nil(t, err)
+
+		decoded := new(wrappers.StringValue)
+		err = flu.DecodeFrom(buf.Bytes(), flu.Protobuf{Value: decoded, JSON: json})
+		assert.Nil(t, err)
+		assert.Equal(t, "test", decoded.Value)
+	}
+}
+
+func TestGob(t *testing.T) {
+	type Value struct {
+		Name  string
+		Items []int
+	}
+
+	value := Value{Name: "test", Items: []int{1, 2, 3}}
+	buf := new(flu.ByteBuffer)
+	err := flu.EncodeTo(flu.Gob{Value: value}, buf)
+	assert.Nil(t, err)
+
+	decoded := new(Value)
+	err = flu.DecodeFrom(buf.Bytes(), flu.Gob{Value: decoded})
+	assert.Nil(t, err)
+	assert.Equal(t, value, *decoded)
+}
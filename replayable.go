@@ -0,0 +1,119 @@
+package flu
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+
+	"github.com/jfk9w-go/flu/serde"
+)
+
+// DefaultReplayMemoryLimit is the memory limit used by Replayable
+// when no positive limit is provided.
+var DefaultReplayMemoryLimit = serde.Size{Bytes: 1 << 20}
+
+// ReplayableInput is the Input wrapper which makes one-shot Inputs re-readable.
+// The underlying Input is read once on the first Reader call and cached.
+// The cache is kept in memory until its size exceeds the memory limit,
+// after that it is spilled to a temporary file.
+// Close should be called to clean up the cache.
+type ReplayableInput struct {
+	in     Input
+	limit  serde.Size
+	data   []byte
+	file   string
+	cached bool
+	mu     Mutex
+}
+
+// Replayable wraps the Input with ReplayableInput.
+// If memoryLimit is not positive, DefaultReplayMemoryLimit is used.
+func Replayable(in Input, memoryLimit serde.Size) *ReplayableInput {
+	if memoryLimit.Bytes <= 0 {
+		memoryLimit = DefaultReplayMemoryLimit
+	}
+
+	return &ReplayableInput{in: in, limit: memoryLimit}
+}
+
+func (r *ReplayableInput) Reader() (io.Reader, error) {
+	return r.ReaderContext(context.Background())
+}
+
+func (r *ReplayableInput) ReaderContext(ctx context.Context) (io.Reader, error) {
+	defer r.mu.Lock().Unlock()
+	if !r.cached {
+		if err := r.cache(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.file != "" {
+		return os.Open(r.file)
+	}
+
+	return bytes.NewReader(r.data), nil
+}
+
+func (r *ReplayableInput) cache(ctx context.Context) error {
+	source, err := ContextReader(ctx, r.in)
+	if err != nil {
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := io.CopyN(buf, source, r.limit.Bytes+1); err == io.EOF {
+		r.data, r.cached = buf.Bytes(), true
+		return Close(source)
+	} else if err != nil {
+		_ = CloseWithError(source, err)
+		return err
+	}
+
+	file, err := os.CreateTemp("", "flu-replay-*")
+	if err != nil {
+		_ = CloseWithError(source, err)
+		return err
+	}
+
+	if _, err := io.Copy(file, io.MultiReader(buf, source)); err != nil {
+		_ = CloseWithError(source, err)
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return err
+	}
+
+	if err := Close(source); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+
+	r.file, r.cached = file.Name(), true
+	return nil
+}
+
+// Spilled checks if the cache has been spilled to a temporary file.
+func (r *ReplayableInput) Spilled() bool {
+	defer r.mu.Lock().Unlock()
+	return r.file != ""
+}
+
+// Close drops the cache and removes the temporary file (if any).
+// The underlying Input will be read again on the next Reader call.
+func (r *ReplayableInput) Close() error {
+	defer r.mu.Lock().Unlock()
+	file := r.file
+	r.data, r.file, r.cached = nil, "", false
+	if file != "" {
+		return os.Remove(file)
+	}
+
+	return nil
+}
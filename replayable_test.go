@@ -0,0 +1,42 @@
+package flu_test
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jfk9w-go/flu"
+	"github.com/jfk9w-go/flu/serde"
+	"github.com/stretchr/testify/assert"
+)
+
+type oneShotInput struct {
+	data string
+	read bool
+}
+
+func (in *oneShotInput) Reader() (io.Reader, error) {
+	if in.read {
+		return nil, os.ErrClosed
+	}
+
+	in.read = true
+	return strings.NewReader(in.data), nil
+}
+
+func TestReplayable(t *testing.T) {
+	for _, limit := range []int64{1 << 10, 4} {
+		in := flu.Replayable(&oneShotInput{data: "replayable data"}, serde.Size{Bytes: limit})
+		for i := 0; i < 3; i++ {
+			text := new(flu.PlainText)
+			err := flu.DecodeFrom(in, text)
+			assert.Nil(t, err)
+			assert.Equal(t, "replayable data", text.Value)
+		}
+
+		assert.Equal(t, limit < 10, in.Spilled())
+		assert.Nil(t, in.Close())
+		assert.False(t, in.Spilled())
+	}
+}
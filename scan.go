@@ -0,0 +1,154 @@
+package flu
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jfk9w-go/flu/serde"
+)
+
+// ErrStopScan may be returned from a Scanner callback
+// to stop scanning without an error.
+var ErrStopScan = errors.New("stop scan")
+
+// ScanError is returned when a record can not be read or processed.
+type ScanError struct {
+	// Line is the 1-based record number
+	// (which is the line number when scanning lines).
+	Line int
+	Err  error
+}
+
+func (e ScanError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+func (e ScanError) Unwrap() error {
+	return e.Err
+}
+
+// Scanner reads the Input record by record without buffering the whole stream.
+// Lines are scanned by default (trailing \r is stripped).
+type Scanner struct {
+	// In is the underlying Input.
+	In Input
+	// Delimiter is the record delimiter.
+	// Defaults to '\n'.
+	Delimiter byte
+	// Split is the custom split function.
+	// Overrides Delimiter if set.
+	Split bufio.SplitFunc
+	// MaxRecordSize is the maximum record size.
+	// Defaults to bufio.MaxScanTokenSize.
+	MaxRecordSize serde.Size
+}
+
+// Scan calls the function for each record.
+// The record slice is valid only until the function returns.
+// Errors (including callback errors) are returned as ScanError.
+func (s Scanner) Scan(fun func(line int, record []byte) error) error {
+	return s.ScanContext(context.Background(), fun)
+}
+
+// ScanContext calls the function for each record
+// aborting the reading as soon as the context is done.
+func (s Scanner) ScanContext(ctx context.Context, fun func(line int, record []byte) error) error {
+	r, err := ContextReader(ctx, s.In)
+	if err != nil {
+		return err
+	}
+
+	if err := s.scan(r, fun); err != nil {
+		_ = CloseWithError(r, err)
+		return err
+	}
+
+	return Close(r)
+}
+
+// scan reads the records from the io.Reader without closing it.
+func (s Scanner) scan(r io.Reader, fun func(line int, record []byte) error) error {
+	max := bufio.MaxScanTokenSize
+	if s.MaxRecordSize.Bytes > 0 {
+		max = int(s.MaxRecordSize.Bytes)
+	}
+
+	// one extra byte for the delimiter
+	max++
+	size := 4096
+	if size > max {
+		size = max
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, size), max)
+	scanner.Split(s.split())
+	line := 0
+	for scanner.Scan() {
+		line++
+		if err := fun(line, scanner.Bytes()); err != nil {
+			if err == ErrStopScan {
+				return nil
+			}
+
+			return ScanError{Line: line, Err: err}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return ScanError{Line: line + 1, Err: err}
+	}
+
+	return nil
+}
+
+// ScanValues decodes each non-empty record using the codec.
+// The values may be a pointer to slice (records are appended),
+// a channel (which is closed after scanning) or a func(T) error callback.
+func (s Scanner) ScanValues(codec CodecFunc, values interface{}) error {
+	sink, err := newValueSink(values)
+	if err != nil {
+		return err
+	}
+
+	defer sink.Close()
+	return s.Scan(func(line int, record []byte) error {
+		if len(bytes.TrimSpace(record)) == 0 {
+			return nil
+		}
+
+		ptr := sink.New()
+		if err := codec(ptr.Interface()).DecodeFrom(bytes.NewReader(record)); err != nil {
+			return err
+		}
+
+		return sink.Put(ptr)
+	})
+}
+
+func (s Scanner) split() bufio.SplitFunc {
+	if s.Split != nil {
+		return s.Split
+	}
+
+	if s.Delimiter == 0 || s.Delimiter == '\n' {
+		return bufio.ScanLines
+	}
+
+	delimiter := s.Delimiter
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if i := bytes.IndexByte(data, delimiter); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+
+		return 0, nil, nil
+	}
+}
@@ -0,0 +1,80 @@
+package flu_test
+
+import (
+	"bufio"
+	"errors"
+	"testing"
+
+	"github.com/jfk9w-go/flu"
+	"github.com/jfk9w-go/flu/serde"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanner_Scan(t *testing.T) {
+	scanner := flu.Scanner{In: flu.Bytes("one\r\ntwo\n\nthree")}
+	var lines []string
+	err := scanner.Scan(func(line int, record []byte) error {
+		lines = append(lines, string(record))
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"one", "two", "", "three"}, lines)
+}
+
+func TestScanner_Delimiter(t *testing.T) {
+	scanner := flu.Scanner{In: flu.Bytes("a;b;c"), Delimiter: ';'}
+	var records []string
+	err := scanner.Scan(func(line int, record []byte) error {
+		records = append(records, string(record))
+		if line == 2 {
+			return flu.ErrStopScan
+		}
+
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, records)
+}
+
+func TestScanner_MaxRecordSize(t *testing.T) {
+	scanner := flu.Scanner{In: flu.Bytes("12345\n123456\n"), MaxRecordSize: serde.Size{Bytes: 5}}
+	err := scanner.Scan(func(line int, record []byte) error { return nil })
+	assert.Equal(t, flu.ScanError{Line: 2, Err: bufio.ErrTooLong}, err)
+
+	failure := errors.New("failure")
+	err = flu.Scanner{In: flu.Bytes("a\nb\n")}.Scan(func(line int, record []byte) error {
+		if line == 2 {
+			return failure
+		}
+
+		return nil
+	})
+
+	assert.True(t, errors.Is(err, failure))
+	assert.Equal(t, 2, err.(flu.ScanError).Line)
+}
+
+func TestScanner_ScanValues(t *testing.T) {
+	type Record struct {
+		ID int `json:"id"`
+	}
+
+	codec := func(value interface{}) flu.Codec { return flu.JSON{Value: value} }
+	scanner := flu.Scanner{In: flu.Bytes("{\"id\": 1}\n\n{\"id\": 2}\n{\"id\": x}\n")}
+
+	var records []Record
+	err := scanner.ScanValues(codec, &records)
+	assert.Equal(t, 4, err.(flu.ScanError).Line)
+	assert.Equal(t, []Record{{ID: 1}, {ID: 2}}, records)
+
+	records = nil
+	err = scanner.ScanValues(codec, func(record Record) error {
+		records = append(records, record)
+		return flu.ErrStopScan
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []Record{{ID: 1}}, records)
+}
@@ -8,6 +8,8 @@ import (
 	yaml "gopkg.in/yaml.v3"
 )
 
+// Duration is a time.Duration decoded from a string (like "1m30s")
+// in JSON and YAML.
 type Duration struct {
 	time.Duration
 }
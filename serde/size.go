@@ -21,24 +21,23 @@ var SizeUnits = map[string]int64{
 	"Tb": 1 << 40,
 }
 
+// Size is a byte size decoded from a string (like "10Mb", see SizeUnits)
+// in JSON and YAML.
 type Size struct {
 	Bytes int64
 }
 
+// String formats the size using the largest unit
+// which divides it without remainder (like "1536b" or "3Mb").
 func (s Size) String() string {
-	var whole int64
-	var finalUnit string
-	for unit, divisor := range SizeUnits {
-		quotient := s.Bytes / divisor
-		if quotient > 0 {
-			if whole == 0 || quotient < whole {
-				whole = quotient
-				finalUnit = unit
-			}
+	unit, divisor := "b", int64(1)
+	for u, d := range SizeUnits {
+		if d > divisor && s.Bytes != 0 && s.Bytes%d == 0 {
+			unit, divisor = u, d
 		}
 	}
 
-	return fmt.Sprintf("%d%s", whole, finalUnit)
+	return fmt.Sprintf("%d%s", s.Bytes/divisor, unit)
 }
 
 func (s *Size) FromString(str string) error {
@@ -37,5 +37,11 @@ func TestSize_ToString(t *testing.T) {
 	assert.Equal(t, "100Mb", size.String())
 
 	size.Bytes = 100<<30 + 100<<20
-	assert.Equal(t, "100Gb", size.String())
+	assert.Equal(t, "102500Mb", size.String())
+
+	size.Bytes = 1536
+	assert.Equal(t, "1536b", size.String())
+
+	size.Bytes = 0
+	assert.Equal(t, "0b", size.String())
 }
@@ -10,6 +10,8 @@ import (
 
 var TimeLayout = "2006-01-02 15:04:05"
 
+// Time is a time.Time encoded as a string in TimeLayout
+// in JSON and YAML. Note that the marshalers have pointer receivers.
 type Time struct {
 	time.Time
 }
@@ -3,7 +3,6 @@ package testutil
 import (
 	"context"
 	"fmt"
-	"io/ioutil"
 	"net"
 	"sync"
 
@@ -11,10 +10,15 @@ import (
 	"github.com/pkg/errors"
 )
 
+// MockServer accepts connections and sends the received data to In.
+// Data is sent as it is read, so a connection may be reused
+// for multiple messages.
 type MockServer struct {
 	Address  string
 	In       chan string
 	listener net.Listener
+	conns    map[net.Conn]bool
+	mu       sync.Mutex
 	work     sync.WaitGroup
 }
 
@@ -32,6 +36,7 @@ func RunMockServer(network string) (*MockServer, error) {
 		Address:  address,
 		In:       make(chan string, 100),
 		listener: listener,
+		conns:    make(map[net.Conn]bool),
 	}
 	mock.work.Add(1)
 	go mock.listen()
@@ -39,7 +44,9 @@ func RunMockServer(network string) (*MockServer, error) {
 }
 
 func (m *MockServer) listen() {
+	var conns sync.WaitGroup
 	defer func() {
+		conns.Wait()
 		close(m.In)
 		m.work.Done()
 	}()
@@ -50,17 +57,46 @@ func (m *MockServer) listen() {
 			return
 		}
 
-		data, err := ioutil.ReadAll(conn)
+		m.mu.Lock()
+		m.conns[conn] = true
+		m.mu.Unlock()
+
+		conns.Add(1)
+		go func() {
+			defer conns.Done()
+			m.read(conn)
+		}()
+	}
+}
+
+func (m *MockServer) read(conn net.Conn) {
+	defer func() {
+		m.mu.Lock()
+		delete(m.conns, conn)
+		m.mu.Unlock()
 		_ = conn.Close()
-		if err != nil {
-			continue
+	}()
+
+	buf := make([]byte, 64<<10)
+	for {
+		n, err := conn.Read(buf)
+		if n > 0 {
+			m.In <- string(buf[:n])
 		}
 
-		m.In <- string(data)
+		if err != nil {
+			return
+		}
 	}
 }
 
 func (m *MockServer) Close() {
 	_ = m.listener.Close()
+	m.mu.Lock()
+	for conn := range m.conns {
+		_ = conn.Close()
+	}
+
+	m.mu.Unlock()
 	m.work.Wait()
 }
@@ -0,0 +1,138 @@
+package flu
+
+import (
+	"context"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+)
+
+// Base64 is the base64 transport encoding Input / Output wrapper.
+// Input data is decoded and Output data is encoded on the fly.
+type Base64 struct {
+	// In is the underlying Input.
+	In Input
+	// Out is the underlying Output.
+	Out Output
+	// Encoding is the base64 encoding (like base64.RawURLEncoding).
+	// Defaults to base64.StdEncoding.
+	Encoding *base64.Encoding
+}
+
+func (b Base64) encoding() *base64.Encoding {
+	if b.Encoding != nil {
+		return b.Encoding
+	}
+
+	return base64.StdEncoding
+}
+
+func (b Base64) Reader() (io.Reader, error) {
+	return b.ReaderContext(context.Background())
+}
+
+func (b Base64) ReaderContext(ctx context.Context) (io.Reader, error) {
+	r, err := ContextReader(ctx, b.In)
+	if err != nil {
+		return nil, err
+	}
+
+	return chainReader{base64.NewDecoder(b.encoding(), r), []interface{}{r}}, nil
+}
+
+func (b Base64) Writer() (io.Writer, error) {
+	return b.WriterContext(context.Background())
+}
+
+func (b Base64) WriterContext(ctx context.Context) (io.Writer, error) {
+	w, err := ContextWriter(ctx, b.Out)
+	if err != nil {
+		return nil, err
+	}
+
+	ew := base64.NewEncoder(b.encoding(), w)
+	return chainWriter{ew, []interface{}{ew, w}}, nil
+}
+
+// Base32 is the base32 transport encoding Input / Output wrapper.
+// Input data is decoded and Output data is encoded on the fly.
+type Base32 struct {
+	// In is the underlying Input.
+	In Input
+	// Out is the underlying Output.
+	Out Output
+	// Encoding is the base32 encoding (like base32.HexEncoding).
+	// Defaults to base32.StdEncoding.
+	Encoding *base32.Encoding
+}
+
+func (b Base32) encoding() *base32.Encoding {
+	if b.Encoding != nil {
+		return b.Encoding
+	}
+
+	return base32.StdEncoding
+}
+
+func (b Base32) Reader() (io.Reader, error) {
+	return b.ReaderContext(context.Background())
+}
+
+func (b Base32) ReaderContext(ctx context.Context) (io.Reader, error) {
+	r, err := ContextReader(ctx, b.In)
+	if err != nil {
+		return nil, err
+	}
+
+	return chainReader{base32.NewDecoder(b.encoding(), r), []interface{}{r}}, nil
+}
+
+func (b Base32) Writer() (io.Writer, error) {
+	return b.WriterContext(context.Background())
+}
+
+func (b Base32) WriterContext(ctx context.Context) (io.Writer, error) {
+	w, err := ContextWriter(ctx, b.Out)
+	if err != nil {
+		return nil, err
+	}
+
+	ew := base32.NewEncoder(b.encoding(), w)
+	return chainWriter{ew, []interface{}{ew, w}}, nil
+}
+
+// Hex is the hexadecimal transport encoding Input / Output wrapper.
+// Input data is decoded and Output data is encoded (in lower case) on the fly.
+type Hex struct {
+	// In is the underlying Input.
+	In Input
+	// Out is the underlying Output.
+	Out Output
+}
+
+func (h Hex) Reader() (io.Reader, error) {
+	return h.ReaderContext(context.Background())
+}
+
+func (h Hex) ReaderContext(ctx context.Context) (io.Reader, error) {
+	r, err := ContextReader(ctx, h.In)
+	if err != nil {
+		return nil, err
+	}
+
+	return chainReader{hex.NewDecoder(r), []interface{}{r}}, nil
+}
+
+func (h Hex) Writer() (io.Writer, error) {
+	return h.WriterContext(context.Background())
+}
+
+func (h Hex) WriterContext(ctx context.Context) (io.Writer, error) {
+	w, err := ContextWriter(ctx, h.Out)
+	if err != nil {
+		return nil, err
+	}
+
+	return chainWriter{hex.NewEncoder(w), []interface{}{w}}, nil
+}
@@ -0,0 +1,53 @@
+package flu_test
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"testing"
+
+	"github.com/jfk9w-go/flu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBase64(t *testing.T) {
+	type Payload struct {
+		Name string `json:"name"`
+	}
+
+	buf := new(flu.ByteBuffer)
+	err := flu.EncodeTo(flu.JSON{Value: Payload{Name: "test?"}}, flu.Base64{Out: buf, Encoding: base64.RawURLEncoding})
+	assert.Nil(t, err)
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString([]byte("{\"name\":\"test?\"}\n")), buf.Unmask().String())
+
+	payload := new(Payload)
+	err = flu.DecodeFrom(flu.Base64{In: buf.Bytes(), Encoding: base64.RawURLEncoding}, flu.JSON{Value: payload})
+	assert.Nil(t, err)
+	assert.Equal(t, "test?", payload.Name)
+
+	err = flu.DecodeFrom(flu.Base64{In: flu.Bytes("not base64!")}, new(flu.PlainText))
+	assert.NotNil(t, err)
+}
+
+func TestBase32(t *testing.T) {
+	buf := new(flu.ByteBuffer)
+	err := flu.EncodeTo(&flu.PlainText{Value: "hello"}, flu.Base32{Out: buf})
+	assert.Nil(t, err)
+	assert.Equal(t, base32.StdEncoding.EncodeToString([]byte("hello")), buf.Unmask().String())
+
+	text := new(flu.PlainText)
+	err = flu.DecodeFrom(flu.Base32{In: buf.Bytes()}, text)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", text.Value)
+}
+
+func TestHex(t *testing.T) {
+	buf := new(flu.ByteBuffer)
+	_, err := flu.Copy(flu.Bytes{0xde, 0xad, 0xbe, 0xef}, flu.Hex{Out: buf})
+	assert.Nil(t, err)
+	assert.Equal(t, "deadbeef", buf.Unmask().String())
+
+	text := new(flu.PlainText)
+	err = flu.DecodeFrom(flu.Hex{In: flu.Bytes("DEADbeef")}, text)
+	assert.Nil(t, err)
+	assert.Equal(t, "\xde\xad\xbe\xef", text.Value)
+}
@@ -0,0 +1,290 @@
+package flu
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jfk9w-go/flu/serde"
+)
+
+// FieldError describes a single validation rule violation.
+type FieldError struct {
+	// Path is the field path (like "servers[0].address").
+	Path string
+	// Rule is the violated rule name.
+	Rule string
+	// Param is the rule parameter.
+	Param string
+}
+
+func (e FieldError) Error() string {
+	switch e.Rule {
+	case "required":
+		return fmt.Sprintf("%s is required", e.Path)
+	case "min":
+		return fmt.Sprintf("%s must be at least %s", e.Path, e.Param)
+	case "max":
+		return fmt.Sprintf("%s must be at most %s", e.Path, e.Param)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", e.Path, e.Param)
+	default:
+		return fmt.Sprintf("%s violates %s=%s", e.Path, e.Rule, e.Param)
+	}
+}
+
+// ValidationError lists all validation rule violations.
+type ValidationError []FieldError
+
+func (e ValidationError) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+// Validate checks the value against `validate` struct tags.
+// Structs, pointers, slices, arrays and maps are traversed recursively.
+// The tag is a comma-separated list of rules:
+//
+//	required     the value must not be zero (or empty for strings, slices and maps)
+//	min=N        numbers must be >= N, strings, slices and maps must have length >= N
+//	max=N        numbers must be <= N, strings, slices and maps must have length <= N
+//	oneof=a b c  the value must be one of the space-separated values
+//
+// Rules other than required are skipped for nil pointers.
+// Bounds for time.Duration and serde.Duration are durations (like "1m30s"),
+// bounds for serde.Size are sizes (like "10Mb").
+// Field paths use the names from json or yaml tags if present.
+// Violations are returned as ValidationError.
+func Validate(value interface{}) error {
+	var errs ValidationError
+	if err := validate(reflect.ValueOf(value), "", &errs); err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// Validated wraps the DecoderFrom so that the decoded value is validated
+// (see Validate). If the decoder has a Value field (like JSON or YAML),
+// its contents are validated, otherwise the decoder itself is.
+func Validated(decoder DecoderFrom) DecoderFrom {
+	return validated{decoder}
+}
+
+type validated struct {
+	DecoderFrom
+}
+
+func (v validated) DecodeFrom(r io.Reader) error {
+	if err := v.DecoderFrom.DecodeFrom(r); err != nil {
+		return err
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(v.DecoderFrom))
+	if value.Kind() == reflect.Struct {
+		if field := value.FieldByName("Value"); field.IsValid() && field.Kind() == reflect.Interface {
+			return Validate(field.Interface())
+		}
+	}
+
+	return Validate(v.DecoderFrom)
+}
+
+var (
+	durationType      = reflect.TypeOf(time.Duration(0))
+	serdeDurationType = reflect.TypeOf(serde.Duration{})
+	serdeSizeType     = reflect.TypeOf(serde.Size{})
+)
+
+func validate(value reflect.Value, path string, errs *ValidationError) error {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !value.IsNil() {
+			return validate(value.Elem(), path, errs)
+		}
+	case reflect.Struct:
+		if value.Type() == serdeDurationType || value.Type() == serdeSizeType {
+			return nil
+		}
+
+		valueType := value.Type()
+		for i := 0; i < valueType.NumField(); i++ {
+			field := valueType.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+
+			fieldPath := path
+			if !field.Anonymous {
+				fieldPath = joinFieldPath(path, fieldName(field))
+			}
+
+			fieldValue := value.Field(i)
+			if tag, ok := field.Tag.Lookup("validate"); ok {
+				if err := validateRules(fieldValue, fieldPath, tag, errs); err != nil {
+					return err
+				}
+			}
+
+			if err := validate(fieldValue, fieldPath, errs); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := validate(value.Index(i), fmt.Sprintf("%s[%d]", path, i), errs); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		// sort keys so that errors are reported in a stable order
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+
+		for _, key := range keys {
+			if err := validate(value.MapIndex(key), fmt.Sprintf("%s[%v]", path, key), errs); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "yaml"} {
+		if name := strings.Split(field.Tag.Get(key), ",")[0]; name != "" && name != "-" {
+			return name
+		}
+	}
+
+	return field.Name
+}
+
+func joinFieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+func validateRules(value reflect.Value, path, tag string, errs *ValidationError) error {
+	for _, rule := range strings.Split(tag, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		var param string
+		if i := strings.Index(rule, "="); i >= 0 {
+			rule, param = rule[:i], rule[i+1:]
+		}
+
+		ok, err := checkRule(value, rule, param)
+		if err != nil {
+			return fmt.Errorf("%s: invalid validate rule %s=%s: %w", path, rule, param, err)
+		}
+
+		if !ok {
+			*errs = append(*errs, FieldError{Path: path, Rule: rule, Param: param})
+			if rule == "required" {
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
+func checkRule(value reflect.Value, rule, param string) (bool, error) {
+	if rule != "required" {
+		// nil pointers are considered optional values
+		for value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				return true, nil
+			}
+
+			value = value.Elem()
+		}
+	}
+
+	switch rule {
+	case "required":
+		switch value.Kind() {
+		case reflect.String, reflect.Slice, reflect.Map:
+			return value.Len() > 0, nil
+		default:
+			return !value.IsZero(), nil
+		}
+	case "min", "max":
+		actual, bound, err := compareBound(value, param)
+		if err != nil {
+			return false, err
+		}
+
+		if rule == "min" {
+			return actual >= bound, nil
+		} else {
+			return actual <= bound, nil
+		}
+	case "oneof":
+		actual := fmt.Sprint(value.Interface())
+		for _, option := range strings.Fields(param) {
+			if actual == option {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	default:
+		return false, fmt.Errorf("unknown rule")
+	}
+}
+
+// compareBound returns the value magnitude along with the parsed bound.
+func compareBound(value reflect.Value, param string) (float64, float64, error) {
+	switch value.Type() {
+	case durationType:
+		bound, err := time.ParseDuration(param)
+		return float64(value.Int()), float64(bound), err
+	case serdeDurationType:
+		bound, err := time.ParseDuration(param)
+		return float64(value.Interface().(serde.Duration).Duration), float64(bound), err
+	case serdeSizeType:
+		var bound serde.Size
+		err := bound.FromString(param)
+		return float64(value.Interface().(serde.Size).Bytes), float64(bound.Bytes), err
+	}
+
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bound, err := strconv.ParseFloat(param, 64)
+		return float64(value.Int()), bound, err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		bound, err := strconv.ParseFloat(param, 64)
+		return float64(value.Uint()), bound, err
+	case reflect.Float32, reflect.Float64:
+		bound, err := strconv.ParseFloat(param, 64)
+		return value.Float(), bound, err
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		bound, err := strconv.Atoi(param)
+		return float64(value.Len()), float64(bound), err
+	default:
+		return 0, 0, fmt.Errorf("unsupported type %s", value.Type())
+	}
+}
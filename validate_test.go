@@ -0,0 +1,101 @@
+package flu_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jfk9w-go/flu"
+	"github.com/jfk9w-go/flu/serde"
+	"github.com/stretchr/testify/assert"
+)
+
+type validateTestServer struct {
+	Address string `yaml:"address" validate:"required"`
+	Weight  *int   `yaml:"weight" validate:"min=1,max=10"`
+}
+
+type validateTestConfig struct {
+	Name     string                         `yaml:"name" validate:"required,max=5"`
+	Mode     string                         `yaml:"mode" validate:"oneof=fast slow"`
+	Workers  int                            `yaml:"workers" validate:"min=1,max=10"`
+	Timeout  serde.Duration                 `yaml:"timeout" validate:"min=1s,max=1m"`
+	Limit    serde.Size                     `yaml:"limit" validate:"max=1Mb"`
+	Interval time.Duration                  `validate:"min=1s"`
+	Servers  []validateTestServer           `yaml:"servers" validate:"required"`
+	Named    map[string]*validateTestServer `yaml:"named"`
+}
+
+func TestValidate(t *testing.T) {
+	weight := 11
+	config := validateTestConfig{
+		Name:     "too long",
+		Mode:     "medium",
+		Workers:  0,
+		Timeout:  serde.Duration{Duration: time.Hour},
+		Limit:    serde.Size{Bytes: 2 << 20},
+		Interval: time.Millisecond,
+		Servers:  []validateTestServer{{Address: "localhost"}, {Weight: &weight}},
+		Named:    map[string]*validateTestServer{"main": {}},
+	}
+
+	err := flu.Validate(&config)
+	assert.Equal(t, flu.ValidationError{
+		{Path: "name", Rule: "max", Param: "5"},
+		{Path: "mode", Rule: "oneof", Param: "fast slow"},
+		{Path: "workers", Rule: "min", Param: "1"},
+		{Path: "timeout", Rule: "max", Param: "1m"},
+		{Path: "limit", Rule: "max", Param: "1Mb"},
+		{Path: "Interval", Rule: "min", Param: "1s"},
+		{Path: "servers[1].address", Rule: "required"},
+		{Path: "servers[1].weight", Rule: "max", Param: "10"},
+		{Path: "named[main].address", Rule: "required"},
+	}, err)
+
+	config = validateTestConfig{
+		Name:     "ok",
+		Mode:     "fast",
+		Workers:  5,
+		Timeout:  serde.Duration{Duration: time.Second},
+		Interval: time.Second,
+		Servers:  []validateTestServer{{Address: "localhost"}},
+	}
+
+	assert.Nil(t, flu.Validate(config))
+}
+
+func TestValidated(t *testing.T) {
+	config := new(validateTestConfig)
+	err := flu.DecodeFrom(flu.Bytes("name: test\nmode: slow\nworkers: 20\ntimeout: 5s\nservers: [{address: a}]\n"),
+		flu.Validated(flu.YAML{Value: config}))
+	assert.Equal(t, flu.ValidationError{
+		{Path: "workers", Rule: "max", Param: "10"},
+		{Path: "Interval", Rule: "min", Param: "1s"},
+	}, err)
+	assert.Equal(t, "validation failed: workers must be at most 10; Interval must be at least 1s", err.Error())
+
+	type Invalid struct {
+		Value string `validate:"min=x"`
+	}
+
+	assert.NotNil(t, flu.Validate(Invalid{}))
+	_, ok := flu.Validate(Invalid{}).(flu.ValidationError)
+	assert.False(t, ok)
+}
+
+func TestValidate_MapOrder(t *testing.T) {
+	type Item struct {
+		Name string `json:"name" validate:"required"`
+	}
+
+	items := map[string]Item{"d": {}, "b": {}, "a": {}, "c": {}}
+	expected := flu.ValidationError{
+		{Path: "[a].name", Rule: "required"},
+		{Path: "[b].name", Rule: "required"},
+		{Path: "[c].name", Rule: "required"},
+		{Path: "[d].name", Rule: "required"},
+	}
+
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, expected, flu.Validate(items))
+	}
+}